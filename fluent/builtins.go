@@ -0,0 +1,184 @@
+package fluent
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// builtinFunctions holds the functions every Bundle provides by default
+var builtinFunctions = map[string]Function{
+	"NUMBER":   numberFunction,
+	"DATETIME": dateTimeFunction,
+}
+
+// numberFunction implements the NUMBER builtin function.
+// It accepts a number as its only positional argument and applies the named arguments as formatting options.
+func numberFunction(positional []Value, named map[string]Value) Value {
+	if len(positional) == 0 {
+		return &ErrorValue{Err: errors.New("a number is required as the first argument")}
+	}
+
+	var number *NumberValue
+	switch arg := positional[0].(type) {
+	case *NumberValue:
+		number = arg
+	case *NoValue:
+		return &NoValue{value: "NUMBER(" + arg.value + ")"}
+	default:
+		return &ErrorValue{Err: fmt.Errorf("a number is required as the first argument, got '%s'", arg.String())}
+	}
+
+	options := DefaultNumberFormatOptions()
+	if number.Options != nil {
+		copied := *number.Options
+		options = &copied
+	}
+
+	for name, value := range named {
+		switch name {
+		case "type":
+			switch numberType := value.String(); numberType {
+			case NumberTypeCardinal, NumberTypeOrdinal:
+				options.Type = numberType
+			default:
+				return &ErrorValue{Err: fmt.Errorf("'%s' is no valid plural type", numberType)}
+			}
+		case "style":
+			switch style := value.String(); style {
+			case NumberStyleDecimal, NumberStylePercent:
+				options.Style = style
+			case "currency":
+				return &ErrorValue{Err: errors.New("the currency style is not supported")}
+			default:
+				return &ErrorValue{Err: fmt.Errorf("'%s' is no valid number style", style)}
+			}
+		case "useGrouping":
+			options.UseGrouping = value.String() != "false"
+		case "minimumIntegerDigits":
+			if err := setIntOption(&options.MinimumIntegerDigits, name, value, 1, 21); err != nil {
+				return &ErrorValue{Err: err}
+			}
+		case "minimumFractionDigits":
+			if err := setIntOption(&options.MinimumFractionDigits, name, value, 0, 20); err != nil {
+				return &ErrorValue{Err: err}
+			}
+		case "maximumFractionDigits":
+			if err := setIntOption(&options.MaximumFractionDigits, name, value, 0, 20); err != nil {
+				return &ErrorValue{Err: err}
+			}
+		case "minimumSignificantDigits":
+			if err := setIntOption(&options.MinimumSignificantDigits, name, value, 1, 21); err != nil {
+				return &ErrorValue{Err: err}
+			}
+		case "maximumSignificantDigits":
+			if err := setIntOption(&options.MaximumSignificantDigits, name, value, 1, 21); err != nil {
+				return &ErrorValue{Err: err}
+			}
+		}
+	}
+
+	// Like Intl.NumberFormat, conflicting limits are rejected instead of being adjusted
+	if options.MinimumFractionDigits >= 0 && options.MaximumFractionDigits >= 0 &&
+		options.MinimumFractionDigits > options.MaximumFractionDigits {
+		return &ErrorValue{Err: fmt.Errorf("minimumFractionDigits (%d) exceeds maximumFractionDigits (%d)",
+			options.MinimumFractionDigits, options.MaximumFractionDigits)}
+	}
+	if options.MinimumSignificantDigits > 0 && options.MaximumSignificantDigits > 0 &&
+		options.MinimumSignificantDigits > options.MaximumSignificantDigits {
+		return &ErrorValue{Err: fmt.Errorf("minimumSignificantDigits (%d) exceeds maximumSignificantDigits (%d)",
+			options.MinimumSignificantDigits, options.MaximumSignificantDigits)}
+	}
+
+	return &NumberValue{
+		value:   number.value,
+		Options: options,
+	}
+}
+
+// dateTimeFunction implements the DATETIME builtin function.
+// It accepts a date time or a number of milliseconds since the Unix epoch as its only positional argument
+// and applies the named arguments as formatting options.
+func dateTimeFunction(positional []Value, named map[string]Value) Value {
+	if len(positional) == 0 {
+		return &ErrorValue{Err: errors.New("a date time is required as the first argument")}
+	}
+
+	var dateTime *DateTimeValue
+	switch arg := positional[0].(type) {
+	case *DateTimeValue:
+		dateTime = arg
+	case *NumberValue:
+		if arg.value.nonFinite != "" {
+			return &ErrorValue{Err: fmt.Errorf("'%s' is no valid date time", arg.Raw())}
+		}
+		dateTime = DateTime(time.UnixMilli(int64(arg.Float64())))
+	case *NoValue:
+		return &NoValue{value: "DATETIME(" + arg.value + ")"}
+	default:
+		return &ErrorValue{Err: fmt.Errorf("a date time is required as the first argument, got '%s'", arg.String())}
+	}
+
+	options := &DateTimeFormatOptions{}
+	if dateTime.Options != nil {
+		copied := *dateTime.Options
+		options = &copied
+	}
+
+	for name, value := range named {
+		switch name {
+		case "dateStyle":
+			options.DateStyle = value.String()
+		case "timeStyle":
+			options.TimeStyle = value.String()
+		case "weekday":
+			options.Weekday = value.String()
+		case "year":
+			options.Year = value.String()
+		case "month":
+			options.Month = value.String()
+		case "day":
+			options.Day = value.String()
+		case "hour":
+			options.Hour = value.String()
+		case "minute":
+			options.Minute = value.String()
+		case "second":
+			options.Second = value.String()
+		case "hour12":
+			options.Hour12 = value.String()
+		case "timeZone":
+			if _, err := time.LoadLocation(value.String()); err != nil {
+				return &ErrorValue{Err: fmt.Errorf("'%s' is no valid time zone", value.String())}
+			}
+			options.TimeZone = value.String()
+		case "timeZoneName":
+			options.TimeZoneName = value.String()
+		}
+	}
+
+	if err := options.validate(); err != nil {
+		return &ErrorValue{Err: err}
+	}
+
+	return &DateTimeValue{
+		Value:   dateTime.Value,
+		Options: options,
+	}
+}
+
+// setIntOption sets the target to the integer the given value represents.
+// Like Intl.NumberFormat does, it rejects values that are no integers or exceed the range of the option.
+func setIntOption(target *int, name string, value Value, min, max int) error {
+	raw := value.String()
+	if number, ok := value.(*NumberValue); ok {
+		raw = number.Raw()
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed < min || parsed > max {
+		return fmt.Errorf("'%s' is out of range for %s, expected an integer between %d and %d", raw, name, min, max)
+	}
+	*target = parsed
+	return nil
+}
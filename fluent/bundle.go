@@ -1,21 +1,51 @@
 package fluent
 
 import (
-	"fmt"
 	"github.com/lus/fluent.go/fluent/parser/ast"
 	"golang.org/x/text/language"
 	"strings"
+	"sync"
+	"sync/atomic"
 )
 
 // Bundle represents a collection of messages and terms collected from one or many resources.
 // It provides the main API to format messages.
+//
+// A Bundle is safe for concurrent use by multiple goroutines. Formatting never blocks: every formatting call operates on an
+// immutable snapshot of the messages, terms and settings taken when the call starts, so resources added or settings changed
+// concurrently only affect subsequent calls. Adding resources and changing settings is serialized internally.
 type Bundle struct {
-	locales  []language.Tag
-	messages map[string]*ast.Message
-	terms    map[string]*ast.Term
+	locales   []language.Tag
+	functions map[string]Function
+
+	// pluralLocales are the locales and their parents plural rules are looked up for, see pluralLocales
+	pluralLocales []language.Tag
+
+	// mutex serializes the writers; state holds the current *bundleState and is replaced as a whole on every change
+	mutex sync.Mutex
+	state atomic.Value
 }
 
-// NewBundle creates a new empty bundle
+// bundleState is an immutable snapshot of the mutable parts of a Bundle.
+// Its maps must never be modified after the snapshot has been published.
+type bundleState struct {
+	messages           map[string]*ast.Message
+	terms              map[string]*ast.Term
+	maxPlaceables      int
+	maxPlaceableLength int
+	useIsolating       bool
+}
+
+// The default limits protecting against the exponential expansion of placeables; these match the ones of fluent.js
+const (
+	DefaultMaxPlaceables      = 100
+	DefaultMaxPlaceableLength = 2500
+)
+
+// NewBundle creates a new empty bundle.
+// Plural rules are taken from the first locale, parent locale or base language of the primary and fallback locales
+// that has CLDR plural rules or a rule registered using RegisterPluralRule.
+// The builtin functions (NUMBER and DATETIME) are registered automatically.
 func NewBundle(primaryLocale language.Tag, fallbackLocales ...language.Tag) *Bundle {
 	locales := make([]language.Tag, 0, len(fallbackLocales)+1)
 	locales = append(locales, primaryLocale)
@@ -23,137 +53,171 @@ func NewBundle(primaryLocale language.Tag, fallbackLocales ...language.Tag) *Bun
 		locales = append(locales, fallback)
 	}
 
-	return &Bundle{
-		locales:  locales,
-		messages: make(map[string]*ast.Message),
-		terms:    make(map[string]*ast.Term),
+	functions := make(map[string]Function, len(builtinFunctions))
+	for name, function := range builtinFunctions {
+		functions[name] = function
+	}
+
+	bundle := &Bundle{
+		locales:       locales,
+		functions:     functions,
+		pluralLocales: pluralLocales(locales),
 	}
+	bundle.state.Store(&bundleState{
+		messages:           make(map[string]*ast.Message),
+		terms:              make(map[string]*ast.Term),
+		maxPlaceables:      DefaultMaxPlaceables,
+		maxPlaceableLength: DefaultMaxPlaceableLength,
+		useIsolating:       true,
+	})
+	return bundle
+}
+
+// snapshot returns the current state of the bundle
+func (bundle *Bundle) snapshot() *bundleState {
+	return bundle.state.Load().(*bundleState)
+}
+
+// update applies the given modification to a copy of the current state and publishes it afterwards.
+// The copy shares the maps of the current state; modifications replacing them have to clone them first.
+func (bundle *Bundle) update(modify func(state *bundleState)) {
+	bundle.mutex.Lock()
+	defer bundle.mutex.Unlock()
+	state := *bundle.snapshot()
+	modify(&state)
+	bundle.state.Store(&state)
+}
+
+// cloneEntries replaces the maps of the state with copies that may be modified safely
+func (state *bundleState) cloneEntries() {
+	messages := make(map[string]*ast.Message, len(state.messages))
+	for id, message := range state.messages {
+		messages[id] = message
+	}
+	terms := make(map[string]*ast.Term, len(state.terms))
+	for id, term := range state.terms {
+		terms[id] = term
+	}
+	state.messages = messages
+	state.terms = terms
+}
+
+// SetUseIsolating sets whether placeables are wrapped in the Unicode bidi isolation marks FSI (U+2068) and PDI (U+2069).
+// This is enabled by default and ensures that e.g. Latin variables render correctly in Arabic or Hebrew messages.
+// Placeables are not isolated if they are the only element of their pattern or if they contain a literal.
+func (bundle *Bundle) SetUseIsolating(useIsolating bool) {
+	bundle.update(func(state *bundleState) {
+		state.useIsolating = useIsolating
+	})
+}
+
+// SetMaxPlaceables sets the maximum amount of placeables that may be expanded while formatting a single message.
+// Exceeding it aborts formatting with ErrTooManyPlaceables. A value of 0 or less disables the limit.
+// This protects against resources that reference messages many times ("billion laughs").
+func (bundle *Bundle) SetMaxPlaceables(max int) {
+	bundle.update(func(state *bundleState) {
+		state.maxPlaceables = max
+	})
+}
+
+// SetMaxPlaceableLength sets the maximum amount of characters a single placeable may resolve to.
+// Exceeding it aborts formatting with ErrPlaceableTooLong. A value of 0 or less disables the limit.
+func (bundle *Bundle) SetMaxPlaceableLength(max int) {
+	bundle.update(func(state *bundleState) {
+		state.maxPlaceableLength = max
+	})
 }
 
 // AddResource adds a Resource to the Bundle.
 // If a message or term was already defined by another resource, an error is raised and the entry is skipped.
+// The errors are prefixed with the name of the resource, if it has one.
+// The entries of the resource become visible to formatting calls at once.
 func (bundle *Bundle) AddResource(resource *Resource) (errs []error) {
-	for _, message := range resource.messages {
-		id := message.ID.Name
-		if bundle.messages[id] != nil {
-			errs = append(errs, fmt.Errorf("message '%s' is already defined", id))
-			continue
+	bundle.update(func(state *bundleState) {
+		state.cloneEntries()
+		for _, message := range resource.messages {
+			id := message.ID.Name
+			if state.messages[id] != nil {
+				errs = append(errs, resource.errorf("message '%s' is already defined", id))
+				continue
+			}
+			state.messages[id] = message
 		}
-		bundle.messages[id] = message
-	}
-	for _, term := range resource.terms {
-		id := term.ID.Name
-		if bundle.terms[id] != nil {
-			errs = append(errs, fmt.Errorf("term '%s' is already defined", id))
-			continue
+		for _, term := range resource.terms {
+			id := term.ID.Name
+			if state.terms[id] != nil {
+				errs = append(errs, resource.errorf("term '%s' is already defined", id))
+				continue
+			}
+			state.terms[id] = term
 		}
-		bundle.terms[id] = term
-	}
+	})
 	return
 }
 
 // AddResourceOverriding adds a Resource to the Bundle.
 // If a message or term was already defined by another resource, the already existing one gets overridden.
+// The entries of the resource become visible to formatting calls at once.
 func (bundle *Bundle) AddResourceOverriding(resource *Resource) {
-	for _, message := range resource.messages {
-		bundle.messages[message.ID.Name] = message
-	}
-	for _, term := range resource.terms {
-		bundle.terms[term.ID.Name] = term
-	}
+	bundle.update(func(state *bundleState) {
+		state.cloneEntries()
+		for _, message := range resource.messages {
+			state.messages[message.ID.Name] = message
+		}
+		for _, term := range resource.terms {
+			state.terms[term.ID.Name] = term
+		}
+	})
 }
 
 // A FormatContext holds variables and functions to pass them to Bundle.FormatMessage
 type FormatContext struct {
 	variables map[string]Value
 	functions map[string]Function
+
+	// invalidVariables holds the errors of the variables that could not be converted into a Value
+	invalidVariables map[string]error
 }
 
-// WithVariable creates a FormatContext with a single variable
+// WithVariable creates a FormatContext with a single variable.
+// See WithVariables for the supported types of values.
 func WithVariable(key string, value interface{}) *FormatContext {
-	resolved := resolveValue(value)
-	if resolved == nil {
-		return &FormatContext{
-			variables: nil,
-			functions: nil,
-		}
-	}
-	return &FormatContext{
-		variables: map[string]Value{strings.TrimSpace(key): resolved},
-		functions: nil,
-	}
+	return WithVariables(map[string]interface{}{key: value})
 }
 
-// WithVariables creates a FormatContext with multiple variables
+// WithVariables creates a FormatContext with multiple variables.
+// Values may be Values, strings, booleans, numbers (including *big.Int and *big.Float), time.Time, time.Duration,
+// fmt.Stringer, encoding.TextMarshaler, types based on strings, booleans or numbers and pointers to these.
+// fmt.Stringer takes precedence, so e.g. time.Month is passed as its name instead of a number.
+// Other types can be supported using RegisterConverter. Variables that can not be converted (including nil pointers)
+// are reported as an InvalidVariableError when they are referenced.
 func WithVariables(variables map[string]interface{}) *FormatContext {
 	cleaned := make(map[string]Value, len(variables))
+	var invalid map[string]error
 	for name, variable := range variables {
-		resolved := resolveValue(variable)
-		if resolved == nil {
+		converted, err := convertValue(variable)
+		if err != nil {
+			if invalid == nil {
+				invalid = make(map[string]error)
+			}
+			invalid[strings.TrimSpace(name)] = err
 			continue
 		}
-		cleaned[strings.TrimSpace(name)] = resolved
+		cleaned[strings.TrimSpace(name)] = converted
 	}
 	return &FormatContext{
-		variables: cleaned,
-		functions: nil,
-	}
-}
-
-func resolveValue(value interface{}) Value {
-	if strVal, ok := value.(string); ok {
-		return String(strVal)
+		variables:        cleaned,
+		functions:        nil,
+		invalidVariables: invalid,
 	}
-	if strVal, ok := value.(*StringValue); ok {
-		return strVal
-	}
-	if float32Val, ok := value.(float32); ok {
-		return Number(float32Val)
-	}
-	if float64Val, ok := value.(float64); ok {
-		return Number(float32(float64Val))
-	}
-	if uintVal, ok := value.(uint); ok {
-		return Number(float32(uintVal))
-	}
-	if uint8Val, ok := value.(uint8); ok {
-		return Number(float32(uint8Val))
-	}
-	if uint16Val, ok := value.(uint16); ok {
-		return Number(float32(uint16Val))
-	}
-	if uint32val, ok := value.(uint32); ok {
-		return Number(float32(uint32val))
-	}
-	if uint64val, ok := value.(uint64); ok {
-		return Number(float32(uint64val))
-	}
-	if intVal, ok := value.(int); ok {
-		return Number(float32(intVal))
-	}
-	if int8Val, ok := value.(int8); ok {
-		return Number(float32(int8Val))
-	}
-	if int16Val, ok := value.(int16); ok {
-		return Number(float32(int16Val))
-	}
-	if int32val, ok := value.(int32); ok {
-		return Number(float32(int32val))
-	}
-	if int64val, ok := value.(int64); ok {
-		return Number(float32(int64val))
-	}
-	if numVal, ok := value.(*NumberValue); ok {
-		return numVal
-	}
-	return nil
 }
 
 // WithFunction creates a FormatContext with a single function
 func WithFunction(key string, function Function) *FormatContext {
 	return &FormatContext{
-		variables: nil,
-		functions: map[string]Function{strings.TrimSpace(strings.ToUpper(key)): function},
+		variables:        nil,
+		functions:        map[string]Function{strings.TrimSpace(strings.ToUpper(key)): function},
+		invalidVariables: nil,
 	}
 }
 
@@ -164,19 +228,32 @@ func WithFunctions(functions map[string]Function) *FormatContext {
 		cleaned[strings.TrimSpace(strings.ToUpper(name))] = function
 	}
 	return &FormatContext{
-		variables: nil,
-		functions: cleaned,
+		variables:        nil,
+		functions:        cleaned,
+		invalidVariables: nil,
 	}
 }
 
-// TODO: Builtin functions (NUMBER, DATETIME)
-func assembleContexts(options ...*FormatContext) (map[string]Value, map[string]Function) {
+// assembleContexts merges the given contexts into the variables, functions and variable conversion errors to resolve with;
+// later contexts take precedence over earlier ones
+func assembleContexts(defaultFunctions map[string]Function, options ...*FormatContext) (map[string]Value, map[string]Function, map[string]error) {
 	variables := make(map[string]Value)
-	functions := make(map[string]Function)
+	invalidVariables := make(map[string]error)
+	functions := make(map[string]Function, len(defaultFunctions))
+	for key, function := range defaultFunctions {
+		functions[key] = function
+	}
 	for _, option := range options {
 		if option.variables != nil {
 			for key, variable := range option.variables {
 				variables[key] = variable
+				delete(invalidVariables, key)
+			}
+		}
+		if option.invalidVariables != nil {
+			for key, err := range option.invalidVariables {
+				invalidVariables[key] = err
+				delete(variables, key)
 			}
 		}
 		if option.functions != nil {
@@ -185,34 +262,122 @@ func assembleContexts(options ...*FormatContext) (map[string]Value, map[string]F
 			}
 		}
 	}
-	return variables, functions
+	return variables, functions, invalidVariables
 }
 
 // FormatMessage formats the message with the given key.
 // To pass variables or functions, pass contexts created using WithVariable, WithVariables, WithFunction or WithFunctions.
 // Besides the formatted message, this method returns the errors the resolver stumbled upon during resolving specific values
-// and an optional error if there is no message with the given key.
+// and an optional error if there is no message with the given key, if the message has no value (NoValueError)
+// or if the formatting process was aborted (see Bundle.SetMaxPlaceables).
 // If the resolver returns errors it does not automatically mean that the whole message could not be resolved.
 // It may be just incomplete.
 func (bundle *Bundle) FormatMessage(key string, contexts ...*FormatContext) (string, []error, error) {
-	if bundle.messages[key] == nil {
-		return "", nil, fmt.Errorf("message '%s' does not exist", key)
+	state := bundle.snapshot()
+	msg := state.messages[key]
+	if msg == nil {
+		return "", nil, &UnknownMessageError{ErrorContext: ErrorContext{}, ID: key, Attribute: ""}
+	}
+
+	if msg.Value == nil {
+		return "", nil, &NoValueError{
+			ErrorContext: ErrorContext{MessageID: key, Node: msg, Span: msg.Span},
+			ID:           key,
+		}
+	}
+	return bundle.formatPattern(state, key, msg.Value, contexts)
+}
+
+// FormatAttribute formats the attribute with the given name of the message with the given key.
+// It behaves exactly like FormatMessage, but returns an error if the message has no attribute with the given name.
+func (bundle *Bundle) FormatAttribute(key, attribute string, contexts ...*FormatContext) (string, []error, error) {
+	state := bundle.snapshot()
+	msg := state.messages[key]
+	if msg == nil {
+		return "", nil, &UnknownMessageError{ErrorContext: ErrorContext{}, ID: key, Attribute: ""}
 	}
 
-	msg := bundle.messages[key]
-	variables, functions := assembleContexts(contexts...)
+	for _, attr := range msg.Attributes {
+		if attr.ID.Name == attribute {
+			return bundle.formatPattern(state, key+"."+attribute, attr.Value, contexts)
+		}
+	}
+	return "", nil, &UnknownMessageError{
+		ErrorContext: ErrorContext{MessageID: key, Node: msg, Span: msg.Span},
+		ID:           key,
+		Attribute:    attribute,
+	}
+}
+
+// FormatMessageWithAttributes formats the value and all attributes of the message with the given key.
+// The attributes are returned as a map of their names to their formatted values.
+// Messages consisting of attributes only are allowed; their value is formatted as an empty string.
+// The resolver errors of the value and all attributes are combined.
+func (bundle *Bundle) FormatMessageWithAttributes(key string, contexts ...*FormatContext) (string, map[string]string, []error, error) {
+	state := bundle.snapshot()
+	msg := state.messages[key]
+	if msg == nil {
+		return "", nil, nil, &UnknownMessageError{ErrorContext: ErrorContext{}, ID: key, Attribute: ""}
+	}
+
+	var errs []error
+
+	value := ""
+	if msg.Value != nil {
+		formatted, valueErrs, err := bundle.formatPattern(state, key, msg.Value, contexts)
+		errs = append(errs, valueErrs...)
+		if err != nil {
+			return "", nil, errs, err
+		}
+		value = formatted
+	}
+
+	attributes := make(map[string]string, len(msg.Attributes))
+	for _, attr := range msg.Attributes {
+		formatted, attrErrs, err := bundle.formatPattern(state, key+"."+attr.ID.Name, attr.Value, contexts)
+		errs = append(errs, attrErrs...)
+		if err != nil {
+			return "", nil, errs, err
+		}
+		attributes[attr.ID.Name] = formatted
+	}
+
+	return value, attributes, errs, nil
+}
+
+// formatPattern resolves the given pattern of the message, term or attribute with the given name using a fresh resolver
+// operating on the given snapshot of the bundle
+func (bundle *Bundle) formatPattern(state *bundleState, name string, pattern *ast.Pattern, contexts []*FormatContext) (string, []error, error) {
+	variables, functions, invalidVariables := assembleContexts(bundle.functions, contexts...)
 	res := &resolver{
-		bundle:    bundle,
-		params:    nil,
-		variables: variables,
-		functions: functions,
-		errors:    []error{},
+		bundle:           bundle,
+		state:            state,
+		messageID:        name,
+		params:           nil,
+		variables:        variables,
+		functions:        functions,
+		errors:           []error{},
+		invalidVariables: invalidVariables,
+		active:           make(map[*ast.Pattern]bool),
+		activeRefs:       nil,
+		placeables:       0,
+		fatal:            nil,
+	}
+	result := res.resolveEntryPattern(name, pattern).String()
+	if res.fatal != nil {
+		return "", res.errors, res.fatal
 	}
-	result := res.resolvePattern(msg.Value).String()
 	return result, res.errors, nil
 }
 
 // Checks whether the bundle contains a message with the given key.
 func (bundle *Bundle) HasMessage(key string) bool {
-    return bundle.messages[key] != nil
+	return bundle.snapshot().messages[key] != nil
+}
+
+// Locales returns the locales of the bundle; the first one is the primary locale, the other ones are the fallbacks
+func (bundle *Bundle) Locales() []language.Tag {
+	locales := make([]language.Tag, len(bundle.locales))
+	copy(locales, bundle.locales)
+	return locales
 }
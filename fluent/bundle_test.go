@@ -0,0 +1,1015 @@
+package fluent
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lus/fluent.go/fluent/parser/ast"
+	"golang.org/x/text/language"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestBundle creates an English bundle containing the given FTL source.
+// Bidi isolation is disabled to keep the expected results readable.
+func newTestBundle(t *testing.T, source string) *Bundle {
+	return newTestBundleWithLocale(t, language.English, source)
+}
+
+// newTestBundleWithLocale creates a bundle of the given locale containing the given FTL source.
+// Bidi isolation is disabled to keep the expected results readable.
+func newTestBundleWithLocale(t *testing.T, locale language.Tag, source string) *Bundle {
+	resource, errs := NewResource(source)
+	if len(errs) > 0 {
+		t.Fatalf("could not parse test resource: %v", errs)
+	}
+	bundle := NewBundle(locale)
+	if errs := bundle.AddResource(resource); len(errs) > 0 {
+		t.Fatalf("could not add test resource: %v", errs)
+	}
+	bundle.SetUseIsolating(false)
+	return bundle
+}
+
+func TestCyclicReferences(t *testing.T) {
+	bundle := newTestBundle(t, `
+direct = { direct }
+indirect-a = { indirect-b }
+indirect-b = { indirect-c }
+indirect-c = { indirect-a }
+attribute = { attribute.label }
+    .label = { attribute }
+attribute-self = Foo
+    .label = { attribute-self.label }
+term = { -term }
+-term = { -term }
+term-attribute = { -term-attribute.label ->
+   *[other] { -term-attribute }
+}
+-term-attribute = { term-attribute }
+    .label = Label
+repeated = { shared } { shared }
+shared = Shared
+`)
+
+	tests := []struct {
+		key      string
+		expected string
+		chain    string
+	}{
+		{"direct", "{???}", "direct -> direct"},
+		{"indirect-a", "{???}", "indirect-a -> indirect-b -> indirect-c -> indirect-a"},
+		{"attribute", "{???}", "attribute -> attribute.label -> attribute"},
+		{"attribute-self", "Foo", ""},
+		{"term", "{???}", "term -> -term -> -term"},
+		{"term-attribute", "{???}", "term-attribute -> -term-attribute -> term-attribute"},
+		{"repeated", "Shared Shared", ""},
+	}
+
+	for _, test := range tests {
+		result, errs, err := bundle.FormatMessage(test.key)
+		if err != nil {
+			t.Fatalf("message '%s' could not be formatted: %s", test.key, err)
+		}
+		if result != test.expected {
+			t.Fatalf("message '%s' resolved to '%s', expected '%s'", test.key, result, test.expected)
+		}
+		if test.chain == "" {
+			if len(errs) > 0 {
+				t.Fatalf("message '%s' raised unexpected errors: %v", test.key, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || !strings.Contains(errs[0].Error(), test.chain) {
+			t.Fatalf("message '%s' raised %v, expected a cyclic reference error for '%s'", test.key, errs, test.chain)
+		}
+	}
+}
+
+func TestPlaceableLimits(t *testing.T) {
+	bundle := newTestBundle(t, `
+lol0 = LOL
+lol1 = {lol0} {lol0} {lol0} {lol0} {lol0} {lol0} {lol0} {lol0} {lol0} {lol0}
+lol2 = {lol1} {lol1} {lol1} {lol1} {lol1} {lol1} {lol1} {lol1} {lol1} {lol1}
+lol3 = {lol2} {lol2} {lol2} {lol2} {lol2} {lol2} {lol2} {lol2} {lol2} {lol2}
+lol4 = {lol3} {lol3} {lol3} {lol3} {lol3} {lol3} {lol3} {lol3} {lol3} {lol3}
+lol5 = {lol4} {lol4} {lol4} {lol4} {lol4} {lol4} {lol4} {lol4} {lol4} {lol4}
+lol6 = {lol5} {lol5} {lol5} {lol5} {lol5} {lol5} {lol5} {lol5} {lol5} {lol5}
+lol7 = {lol6} {lol6} {lol6} {lol6} {lol6} {lol6} {lol6} {lol6} {lol6} {lol6}
+lol8 = {lol7} {lol7} {lol7} {lol7} {lol7} {lol7} {lol7} {lol7} {lol7} {lol7}
+lol9 = {lol8} {lol8} {lol8} {lol8} {lol8} {lol8} {lol8} {lol8} {lol8} {lol8}
+`)
+
+	// The default limits abort the expansion early
+	result, _, err := bundle.FormatMessage("lol9")
+	if !errors.Is(err, ErrTooManyPlaceables) {
+		t.Fatalf("expected ErrTooManyPlaceables, got '%v'", err)
+	}
+	if result != "" {
+		t.Fatalf("expected an empty result, got '%s'", result)
+	}
+
+	// Messages within the limit are formatted normally
+	result, errs, err := bundle.FormatMessage("lol1")
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v, %v", err, errs)
+	}
+	if result != strings.TrimSpace(strings.Repeat("LOL ", 10)) {
+		t.Fatalf("unexpected result '%s'", result)
+	}
+
+	// Placeables exceeding the maximum length abort the formatting as well
+	bundle.SetMaxPlaceables(0)
+	bundle.SetMaxPlaceableLength(10)
+	result, _, err = bundle.FormatMessage("lol2")
+	if !errors.Is(err, ErrPlaceableTooLong) {
+		t.Fatalf("expected ErrPlaceableTooLong, got '%v'", err)
+	}
+	if result != "" {
+		t.Fatalf("expected an empty result, got '%s'", result)
+	}
+}
+
+func TestBidiIsolation(t *testing.T) {
+	bundle := newTestBundle(t, `
+single = { $name }
+multiple = Hello, { $name }!
+literals = { "Hello" } { 42 } { $name }
+nested = { single }: { multiple }
+select = { $name ->
+   *[other] Dear { $name }
+}
+`)
+	bundle.SetUseIsolating(true)
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"single", "Jane"},
+		{"multiple", "Hello, \u2068Jane\u2069!"},
+		{"literals", "Hello 42 \u2068Jane\u2069"},
+		{"nested", "\u2068Jane\u2069: \u2068Hello, \u2068Jane\u2069!\u2069"},
+		{"select", "Dear \u2068Jane\u2069"},
+	}
+
+	for _, test := range tests {
+		result, errs, err := bundle.FormatMessage(test.key, WithVariable("name", "Jane"))
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("message '%s' could not be formatted: %v, %v", test.key, err, errs)
+		}
+		if result != test.expected {
+			t.Fatalf("message '%s' resolved to %q, expected %q", test.key, result, test.expected)
+		}
+	}
+}
+
+func TestFormatAttributes(t *testing.T) {
+	bundle := newTestBundle(t, `
+login-input = Predefined value
+    .placeholder = email@example.com
+    .aria-label = Login input for { $user }
+    .title = Type your login email
+button =
+    .label = OK
+`)
+
+	result, errs, err := bundle.FormatAttribute("login-input", "aria-label", WithVariable("user", "Jane"))
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("attribute could not be formatted: %v, %v", err, errs)
+	}
+	if result != "Login input for Jane" {
+		t.Fatalf("unexpected attribute value '%s'", result)
+	}
+
+	if _, _, err := bundle.FormatAttribute("login-input", "missing"); err == nil {
+		t.Fatal("expected an error for a missing attribute")
+	}
+	if _, _, err := bundle.FormatAttribute("missing", "placeholder"); err == nil {
+		t.Fatal("expected an error for a missing message")
+	}
+
+	value, attributes, errs, err := bundle.FormatMessageWithAttributes("login-input", WithVariable("user", "Jane"))
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("message could not be formatted: %v, %v", err, errs)
+	}
+	expected := map[string]string{
+		"placeholder": "email@example.com",
+		"aria-label":  "Login input for Jane",
+		"title":       "Type your login email",
+	}
+	if value != "Predefined value" || !reflect.DeepEqual(attributes, expected) {
+		t.Fatalf("unexpected result '%s', %v", value, attributes)
+	}
+
+	value, attributes, errs, err = bundle.FormatMessageWithAttributes("button")
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("message could not be formatted: %v, %v", err, errs)
+	}
+	if value != "" || attributes["label"] != "OK" {
+		t.Fatalf("unexpected result '%s', %v", value, attributes)
+	}
+}
+
+func TestAttributeOnlyEntries(t *testing.T) {
+	source, err := ioutil.ReadFile(filepath.Join("../test", "bundle", "attribute_only.ftl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := newTestBundle(t, string(source))
+
+	// Formatting the value of an attribute-only message directly raises a NoValueError
+	var noValueErr *NoValueError
+	_, _, err = bundle.FormatMessage("button")
+	if !errors.As(err, &noValueErr) || noValueErr.ID != "button" {
+		t.Fatalf("expected a NoValueError for 'button', got '%v'", err)
+	}
+
+	// Referencing the value of an attribute-only message raises a NoValueError as a resolver error
+	result, errs, err := bundle.FormatMessage("references-button")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "{button}" || len(errs) != 1 || !errors.As(errs[0], &noValueErr) {
+		t.Fatalf("unexpected result '%s', %v", result, errs)
+	}
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"references-button-label", "OK"},
+		{"uses-brand", "Firefox"},
+		{"uses-brand-gender", "He"},
+	}
+	for _, test := range tests {
+		result, errs, err := bundle.FormatMessage(test.key)
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("message '%s' could not be formatted: %v, %v", test.key, err, errs)
+		}
+		if result != test.expected {
+			t.Fatalf("message '%s' resolved to '%s', expected '%s'", test.key, result, test.expected)
+		}
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	bundle := newTestBundle(t, `
+greeting = Hello, { $name }!
+`)
+
+	const writers = 4
+	const readers = 8
+	const iterations = 200
+
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				resource, errs := NewResource(fmt.Sprintf("entry-%d-%d = Entry { -brand }\n-brand = Brand %d\n", w, i, w))
+				if len(errs) > 0 {
+					t.Errorf("could not parse resource: %v", errs)
+					return
+				}
+				if i%2 == 0 {
+					bundle.AddResourceOverriding(resource)
+				} else {
+					bundle.AddResource(resource)
+				}
+				bundle.SetMaxPlaceables(DefaultMaxPlaceables + i)
+			}
+		}(w)
+	}
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func(r int) {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				result, errs, err := bundle.FormatMessage("greeting", WithVariable("name", "Jane"))
+				if err != nil || len(errs) > 0 || result != "Hello, Jane!" {
+					t.Errorf("unexpected result '%s', %v, %v", result, errs, err)
+					return
+				}
+				key := fmt.Sprintf("entry-%d-%d", r%writers, i)
+				if bundle.HasMessage(key) {
+					if _, _, err := bundle.FormatMessage(key); err != nil {
+						t.Errorf("message '%s' could not be formatted: %v", key, err)
+						return
+					}
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	for w := 0; w < writers; w++ {
+		for i := 0; i < iterations; i++ {
+			key := fmt.Sprintf("entry-%d-%d", w, i)
+			if !bundle.HasMessage(key) {
+				t.Fatalf("message '%s' was lost", key)
+			}
+		}
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	bundle := newTestBundle(t, `
+variable = { $missing }
+message = { missing }
+message-attribute = { variable.missing }
+term = { -missing }
+term-attribute = { -brand.missing ->
+   *[other] Other
+}
+function = { MISSING() }
+failing-function = { NUMBER("text") }
+cyclic = { cyclic }
+-brand = Brand
+`)
+
+	tests := []struct {
+		key      string
+		expected string
+		check    func(err error) bool
+	}{
+		{"variable", "{$missing}", func(err error) bool {
+			var target *UnknownVariableError
+			return errors.As(err, &target) && target.Name == "missing"
+		}},
+		{"message", "{missing}", func(err error) bool {
+			var target *UnknownMessageError
+			return errors.As(err, &target) && target.ID == "missing" && target.Attribute == ""
+		}},
+		{"message-attribute", "{variable.missing}", func(err error) bool {
+			var target *UnknownMessageError
+			return errors.As(err, &target) && target.ID == "variable" && target.Attribute == "missing"
+		}},
+		{"term", "{missing}", func(err error) bool {
+			var target *UnknownTermError
+			return errors.As(err, &target) && target.ID == "missing"
+		}},
+		{"term-attribute", "Other", func(err error) bool {
+			var target *UnknownTermError
+			return errors.As(err, &target) && target.ID == "brand" && target.Attribute == "missing"
+		}},
+		{"function", "{MISSING}", func(err error) bool {
+			var target *UnknownFunctionError
+			return errors.As(err, &target) && target.Name == "MISSING"
+		}},
+		{"failing-function", "{NUMBER()}", func(err error) bool {
+			var target *FunctionError
+			return errors.As(err, &target) && target.Name == "NUMBER" && target.Err != nil
+		}},
+		{"cyclic", "{???}", func(err error) bool {
+			var target *CyclicReferenceError
+			return errors.As(err, &target) && reflect.DeepEqual(target.Chain, []string{"cyclic", "cyclic"})
+		}},
+	}
+
+	for _, test := range tests {
+		result, errs, err := bundle.FormatMessage(test.key)
+		if err != nil {
+			t.Fatalf("message '%s' could not be formatted: %v", test.key, err)
+		}
+		if result != test.expected {
+			t.Fatalf("message '%s' resolved to '%s', expected '%s'", test.key, result, test.expected)
+		}
+		if len(errs) != 1 || !test.check(errs[0]) {
+			t.Fatalf("message '%s' raised unexpected errors: %v", test.key, errs)
+		}
+	}
+
+	// The errors carry the formatted message, the offending node and its span
+	_, errs, _ := bundle.FormatMessage("variable")
+	var variableErr *UnknownVariableError
+	errors.As(errs[0], &variableErr)
+	if variableErr.MessageID != "variable" || variableErr.Span != [2]uint{14, 22} {
+		t.Fatalf("unexpected error context %+v", variableErr.ErrorContext)
+	}
+	if ref, ok := variableErr.Node.(*ast.VariableReference); !ok || ref.ID.Name != "missing" {
+		t.Fatalf("unexpected error node %v", variableErr.Node)
+	}
+
+	// Missing messages are reported by the bundle directly
+	var messageErr *UnknownMessageError
+	if _, _, err := bundle.FormatMessage("missing"); !errors.As(err, &messageErr) || messageErr.ID != "missing" {
+		t.Fatalf("expected an UnknownMessageError, got '%v'", err)
+	}
+}
+
+func TestOrdinals(t *testing.T) {
+	source := `
+place = { NUMBER($pos, type: "ordinal") ->
+    [zero] zero
+    [one] one
+    [two] two
+    [few] few
+    [many] many
+   *[other] other
+}
+exact = { NUMBER($pos, type: "ordinal") ->
+    [1] exact
+    [one] one
+   *[other] other
+}
+cardinal = { $pos ->
+    [one] one
+    [two] two
+    [few] few
+   *[other] other
+}
+`
+	welsh := language.MustParse("cy")
+	tests := []struct {
+		locale   language.Tag
+		key      string
+		position int64
+		expected string
+	}{
+		{language.English, "place", 1, "one"},
+		{language.English, "place", 2, "two"},
+		{language.English, "place", 3, "few"},
+		{language.English, "place", 4, "other"},
+		{language.English, "place", 11, "other"},
+		{language.English, "place", 12, "other"},
+		{language.English, "place", 21, "one"},
+		{language.English, "place", 22, "two"},
+		{language.English, "place", 103, "few"},
+		{language.English, "exact", 1, "exact"},
+		{language.English, "exact", 101, "one"},
+		{language.English, "cardinal", 2, "other"},
+		{welsh, "place", 0, "zero"},
+		{welsh, "place", 1, "one"},
+		{welsh, "place", 2, "two"},
+		{welsh, "place", 3, "few"},
+		{welsh, "place", 4, "few"},
+		{welsh, "place", 5, "many"},
+		{welsh, "place", 6, "many"},
+		{welsh, "place", 7, "zero"},
+		{welsh, "place", 10, "other"},
+		{welsh, "cardinal", 3, "few"},
+		{language.Italian, "place", 8, "many"},
+		{language.Italian, "place", 11, "many"},
+		{language.Italian, "place", 80, "many"},
+		{language.Italian, "place", 800, "many"},
+		{language.Italian, "place", 1, "other"},
+		{language.Italian, "place", 81, "other"},
+	}
+
+	for _, test := range tests {
+		bundle := newTestBundleWithLocale(t, test.locale, source)
+		result, errs, err := bundle.FormatMessage(test.key, WithVariable("pos", test.position))
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("message '%s' could not be formatted: %v, %v", test.key, err, errs)
+		}
+		if result != test.expected {
+			t.Fatalf("message '%s' resolved to '%s' for %d in '%s', expected '%s'",
+				test.key, result, test.position, test.locale, test.expected)
+		}
+	}
+}
+
+func TestPluralFallbacks(t *testing.T) {
+	klingon := language.MustParse("tlh")
+	RegisterPluralRule(klingon, func(number *NumberValue, ordinal bool) string {
+		if ordinal {
+			return "other"
+		}
+		if number.Raw() == "1" {
+			return "one"
+		}
+		return "few"
+	})
+	defer RegisterPluralRule(klingon, nil)
+
+	source := `
+count = { $count ->
+    [one] one
+    [few] few
+    [many] many
+   *[other] other
+}
+`
+	tests := []struct {
+		locales  []language.Tag
+		count    float64
+		expected string
+	}{
+		{[]language.Tag{language.MustParse("sr-Latn-RS")}, 3, "few"},
+		{[]language.Tag{language.MustParse("ru-Latn")}, 5, "many"},
+		{[]language.Tag{language.MustParse("x-private"), language.Russian}, 3, "few"},
+		{[]language.Tag{language.MustParse("x-private"), language.English}, 3, "other"},
+		{[]language.Tag{language.Japanese, language.Russian}, 3, "other"},
+		{[]language.Tag{klingon}, 1, "one"},
+		{[]language.Tag{klingon}, 3, "few"},
+		{[]language.Tag{language.MustParse("tlh-x-variant")}, 2, "few"},
+		{[]language.Tag{language.MustParse("x-private")}, 1, "other"},
+		// x/text knows these languages, but CLDR defines no plural rules for them
+		{[]language.Tag{language.Make("ewo"), language.French}, 1, "one"},
+		{[]language.Tag{language.Make("dav"), language.Russian}, 3, "few"},
+		{[]language.Tag{language.Make("agq-CM"), language.French}, 0, "one"},
+		{[]language.Tag{language.Make("bas")}, 1, "other"},
+	}
+
+	for _, test := range tests {
+		resource, errs := NewResource(source)
+		if len(errs) > 0 {
+			t.Fatalf("could not parse test resource: %v", errs)
+		}
+		bundle := NewBundle(test.locales[0], test.locales[1:]...)
+		bundle.AddResourceOverriding(resource)
+		result, resolveErrs, err := bundle.FormatMessage("count", WithVariable("count", test.count))
+		if err != nil || len(resolveErrs) > 0 {
+			t.Fatalf("message could not be formatted: %v, %v", err, resolveErrs)
+		}
+		if result != test.expected {
+			t.Fatalf("%v selected '%s' for %v, expected '%s'", test.locales, result, test.count, test.expected)
+		}
+	}
+}
+
+// testPerson is a custom value selecting variants by its gender
+type testPerson struct {
+	name   string
+	gender string
+}
+
+func (person *testPerson) String() string {
+	return person.name
+}
+
+func (person *testPerson) Match(key string, _ language.Tag) bool {
+	return key == person.gender
+}
+
+// testMoney is a custom value formatted depending on the locale
+type testMoney struct {
+	cents int64
+}
+
+func (money *testMoney) String() string {
+	return money.Format(language.English)
+}
+
+func (money *testMoney) Format(locale language.Tag) string {
+	separator := "."
+	if base, _ := locale.Base(); base.String() == "de" {
+		separator = ","
+	}
+	return fmt.Sprintf("%d%s%02d €", money.cents/100, separator, money.cents%100)
+}
+
+func TestCustomValues(t *testing.T) {
+	source := `
+invited = { $person ->
+    [male] { $person } invited you to his party.
+    [female] { $person } invited you to her party.
+   *[other] { $person } invited you to their party.
+}
+price = Price: { $price }
+cheapest = { PRICE() }
+`
+	tests := []struct {
+		locale   language.Tag
+		key      string
+		value    interface{}
+		expected string
+	}{
+		{language.English, "invited", &testPerson{name: "John", gender: "male"}, "John invited you to his party."},
+		{language.English, "invited", &testPerson{name: "Jane", gender: "female"}, "Jane invited you to her party."},
+		{language.English, "invited", &testPerson{name: "Alex", gender: ""}, "Alex invited you to their party."},
+		{language.English, "price", &testMoney{cents: 1250}, "Price: 12.50 €"},
+		{language.German, "price", &testMoney{cents: 1250}, "Price: 12,50 €"},
+		{language.German, "cheapest", nil, "0,99 €"},
+	}
+
+	for _, test := range tests {
+		bundle := newTestBundleWithLocale(t, test.locale, source)
+		result, errs, err := bundle.FormatMessage(test.key,
+			WithVariables(map[string]interface{}{"person": test.value, "price": test.value}),
+			WithFunction("PRICE", func(positional []Value, named map[string]Value) Value {
+				return &testMoney{cents: 99}
+			}))
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("message '%s' could not be formatted: %v, %v", test.key, err, errs)
+		}
+		if result != test.expected {
+			t.Fatalf("message '%s' resolved to '%s', expected '%s'", test.key, result, test.expected)
+		}
+	}
+}
+
+// testGender is a named string type converted using reflection
+type testGender string
+
+// testLevel is a named integer type converted using reflection
+type testLevel uint8
+
+// testTitle is a named integer type implementing fmt.Stringer like enums do
+type testTitle int
+
+func (title testTitle) String() string {
+	return [...]string{"unknown", "male", "female"}[title]
+}
+
+// testIP implements encoding.TextMarshaler
+type testIP [4]byte
+
+func (ip testIP) MarshalText() ([]byte, error) {
+	return []byte(fmt.Sprintf("%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3])), nil
+}
+
+// testStringer implements fmt.Stringer
+type testStringer struct{}
+
+func (testStringer) String() string {
+	return "stringer"
+}
+
+// testConverted is converted using a registered converter
+type testConverted struct {
+	value int
+}
+
+func TestVariableConversion(t *testing.T) {
+	unregister := RegisterConverter(func(value interface{}) (Value, bool) {
+		if converted, ok := value.(testConverted); ok {
+			return NumberFromInt(int64(converted.value * 2)), true
+		}
+		return nil, false
+	})
+	defer unregister()
+
+	bundle := newTestBundle(t, `
+value = { $value }
+count = { $value ->
+    [one] one
+   *[other] other
+}
+month = { $value ->
+    [January] january
+    [March] march
+   *[other] other
+}
+gender = { $value ->
+    [male] male
+    [female] female
+   *[other] other
+}
+`)
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	number := 42
+	tests := []struct {
+		key      string
+		value    interface{}
+		expected string
+	}{
+		{"value", testGender("female"), "female"},
+		{"value", testLevel(3), "3"},
+		{"count", testLevel(1), "one"},
+		{"value", true, "true"},
+		{"value", &number, "42"},
+		{"value", huge, "123,456,789,012,345,678,901,234,567,890"},
+		{"value", big.NewFloat(1234.5), "1,234.5"},
+		{"count", big.NewInt(1), "one"},
+		{"value", 90 * time.Minute, "1h30m0s"},
+		{"value", testIP{127, 0, 0, 1}, "127.0.0.1"},
+		{"value", testStringer{}, "stringer"},
+		{"value", testConverted{value: 21}, "42"},
+		{"value", &time.Time{}, "1/1/1"},
+		// Types based on numbers implementing fmt.Stringer are formatted using String, like fmt does
+		{"value", time.March, "March"},
+		{"count", time.January, "other"},
+		{"month", time.March, "march"},
+		{"gender", testTitle(2), "female"},
+	}
+
+	for _, test := range tests {
+		result, errs, err := bundle.FormatMessage(test.key, WithVariable("value", test.value))
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("message '%s' could not be formatted with %v: %v, %v", test.key, test.value, err, errs)
+		}
+		if result != test.expected {
+			t.Fatalf("message '%s' resolved to '%s' for %v, expected '%s'", test.key, result, test.value, test.expected)
+		}
+	}
+
+	var nilPointer *int
+	var nilValue Value = (*StringValue)(nil)
+	invalid := []interface{}{
+		nil, nilPointer, &nilPointer, (*url.URL)(nil), (*time.Time)(nil), (*big.Int)(nil), nilValue,
+		[]string{"a"}, struct{}{},
+	}
+	for _, value := range invalid {
+		result, errs, err := bundle.FormatMessage("value", WithVariable("value", value))
+		if err != nil {
+			t.Fatalf("message could not be formatted: %v", err)
+		}
+		var invalidErr *InvalidVariableError
+		if len(errs) != 1 || !errors.As(errs[0], &invalidErr) || invalidErr.Name != "value" {
+			t.Fatalf("%#v was not reported as an invalid variable: %v", value, errs)
+		}
+		if result != "{$value}" {
+			t.Fatalf("message resolved to '%s' for the invalid value %#v", result, value)
+		}
+	}
+
+	// Valid values of later contexts take precedence over invalid ones
+	result, errs, err := bundle.FormatMessage("value", WithVariable("value", nil), WithVariable("value", "valid"))
+	if err != nil || len(errs) > 0 || result != "valid" {
+		t.Fatalf("message resolved to '%s' (%v, %v), expected 'valid'", result, err, errs)
+	}
+
+	// Unregistered converters are not consulted anymore
+	unregister()
+	if _, errs, _ := bundle.FormatMessage("value", WithVariable("value", testConverted{value: 21})); len(errs) != 1 {
+		t.Fatalf("the unregistered converter was applied: %v", errs)
+	}
+}
+
+func TestNumberFunction(t *testing.T) {
+	tests := []struct {
+		source   string
+		value    interface{}
+		expected string
+	}{
+		{`{ $n }`, 1234.5, "1,234.5"},
+		{`{ NUMBER($n) }`, 1234.5678, "1,234.568"},
+		{`{ NUMBER($n, minimumFractionDigits: 2) }`, 1234.5, "1,234.50"},
+		{`{ NUMBER($n, maximumFractionDigits: 0) }`, 2.5, "3"},
+		{`{ NUMBER($n, maximumFractionDigits: 1) }`, -0.05, "-0.1"},
+		{`{ NUMBER($n, minimumFractionDigits: 2, maximumFractionDigits: 2) }`, 0.125, "0.13"},
+		{`{ NUMBER($n, useGrouping: "false") }`, 1234567.5, "1234567.5"},
+		{`{ NUMBER($n, minimumIntegerDigits: 3) }`, 7, "007"},
+		{`{ NUMBER($n, style: "percent") }`, 0.256, "26%"},
+		{`{ NUMBER($n, style: "percent", minimumFractionDigits: 1) }`, 0.256, "25.6%"},
+		{`{ NUMBER($n, style: "decimal") }`, 0.256, "0.256"},
+		{`{ NUMBER($n, maximumSignificantDigits: 3) }`, 123456, "123,000"},
+		{`{ NUMBER($n, maximumSignificantDigits: 2) }`, 0.000123456, "0.00012"},
+		{`{ NUMBER($n, minimumSignificantDigits: 5) }`, 1.5, "1.5000"},
+		{`{ NUMBER($n, maximumSignificantDigits: 1) }`, 96, "100"},
+		{`{ NUMBER(NUMBER($n, minimumFractionDigits: 2), useGrouping: "false") }`, 1234, "1234.00"},
+		{`{ NUMBER(1.50) }`, 0, "1.50"},
+		{`{ NUMBER($n, minimumFractionDigits: 1) ->
+    [one] one
+   *[other] other
+}`, 1, "other"},
+		{`{ NUMBER($n, maximumFractionDigits: 0) ->
+    [one] one
+   *[other] other
+}`, 1.2, "one"},
+		{`{ NUMBER($n, style: "percent") ->
+    [one] one
+   *[other] other
+}`, 1, "one"},
+	}
+
+	for _, test := range tests {
+		bundle := newTestBundle(t, "number = "+test.source)
+		result, errs, err := bundle.FormatMessage("number", WithVariable("n", test.value))
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("'%s' could not be formatted: %v, %v", test.source, err, errs)
+		}
+		if result != test.expected {
+			t.Fatalf("'%s' resolved to '%s' for %v, expected '%s'", test.source, result, test.value, test.expected)
+		}
+	}
+
+	// Invalid arguments are reported as function errors
+	invalid := []string{
+		`{ NUMBER() }`,
+		`{ NUMBER("text") }`,
+		`{ NUMBER($n, style: "currency", currency: "EUR") }`,
+		`{ NUMBER($n, style: "unknown") }`,
+		`{ NUMBER($n, minimumIntegerDigits: 0) }`,
+		`{ NUMBER($n, minimumFractionDigits: 1000000000) }`,
+		`{ NUMBER($n, maximumFractionDigits: "many") }`,
+		`{ NUMBER($n, maximumSignificantDigits: 22) }`,
+		`{ NUMBER($n, type: "bogus") }`,
+		`{ NUMBER($n, minimumFractionDigits: 3, maximumFractionDigits: 1) }`,
+		`{ NUMBER($n, minimumSignificantDigits: 5, maximumSignificantDigits: 2) }`,
+	}
+	for _, source := range invalid {
+		bundle := newTestBundle(t, "number = "+source)
+		result, errs, err := bundle.FormatMessage("number", WithVariable("n", 42))
+		if err != nil {
+			t.Fatalf("'%s' could not be formatted: %v", source, err)
+		}
+		var functionErr *FunctionError
+		if len(errs) != 1 || !errors.As(errs[0], &functionErr) || functionErr.Name != "NUMBER" {
+			t.Fatalf("'%s' did not raise a function error: %v", source, errs)
+		}
+		if result != "{NUMBER()}" {
+			t.Fatalf("'%s' resolved to '%s', expected '{NUMBER()}'", source, result)
+		}
+	}
+}
+
+func TestDateTimeFunction(t *testing.T) {
+	// Sunday, 12th of September 2021, 14:05:09 UTC
+	date := time.Date(2021, 9, 12, 14, 5, 9, 0, time.UTC)
+	tests := []struct {
+		locales  []language.Tag
+		source   string
+		expected string
+	}{
+		{[]language.Tag{language.English}, `{ $date }`, "9/12/2021"},
+		{[]language.Tag{language.English}, `{ DATETIME($date) }`, "9/12/2021"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, dateStyle: "full") }`, "Sunday, September 12, 2021"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, dateStyle: "long") }`, "September 12, 2021"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, dateStyle: "medium") }`, "Sep 12, 2021"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, dateStyle: "short") }`, "9/12/21"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, timeStyle: "short") }`, "2:05\u202fPM"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, timeStyle: "medium") }`, "2:05:09\u202fPM"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, timeStyle: "long") }`, "2:05:09\u202fPM UTC"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, dateStyle: "medium", timeStyle: "short") }`, "Sep 12, 2021, 2:05\u202fPM"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, dateStyle: "long", timeStyle: "short") }`, "September 12, 2021 at 2:05\u202fPM"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, timeStyle: "short", hour12: "false") }`, "14:05"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, weekday: "long") }`, "Sunday"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, weekday: "short", month: "short", day: "numeric") }`, "Sun, Sep 12"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, month: "long") }`, "September"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, month: "narrow") }`, "S"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, month: "long", day: "numeric") }`, "September 12"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, year: "numeric", month: "short") }`, "Sep 2021"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, year: "numeric", month: "2-digit", day: "2-digit") }`, "09/12/2021"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, year: "2-digit", month: "numeric", day: "numeric") }`, "9/12/21"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, hour: "numeric", minute: "numeric") }`, "2:05\u202fPM"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, hour: "numeric", minute: "numeric", second: "numeric", hour12: "false") }`, "14:05:09"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, hour: "numeric", minute: "numeric", timeZoneName: "short") }`, "2:05\u202fPM UTC"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, dateStyle: "short", timeStyle: "short", timeZone: "America/New_York") }`, "9/12/21, 10:05\u202fAM"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, timeZoneName: "short") }`, "9/12/2021, UTC"},
+		{[]language.Tag{language.German}, `{ DATETIME($date, timeZoneName: "short") }`, "12.9.2021, UTC"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, minute: "numeric") }`, "5"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, second: "2-digit") }`, "09"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, minute: "numeric", second: "numeric") }`, "05:09"},
+		{[]language.Tag{language.English}, `{ DATETIME($date, weekday: "long", year: "numeric", month: "short", day: "numeric") }`, "Sunday, Sep 12, 2021"},
+		{[]language.Tag{language.English}, `{ DATETIME(1631455509000, dateStyle: "medium") }`, "Sep 12, 2021"},
+		{[]language.Tag{language.BritishEnglish}, `{ DATETIME($date) }`, "12/09/2021"},
+		{[]language.Tag{language.BritishEnglish}, `{ DATETIME($date, timeStyle: "short") }`, "14:05"},
+		{[]language.Tag{language.German}, `{ DATETIME($date) }`, "12.9.2021"},
+		{[]language.Tag{language.German}, `{ DATETIME($date, dateStyle: "full") }`, "Sonntag, 12. September 2021"},
+		{[]language.Tag{language.German}, `{ DATETIME($date, dateStyle: "medium") }`, "12.09.2021"},
+		{[]language.Tag{language.German}, `{ DATETIME($date, dateStyle: "short", timeStyle: "short") }`, "12.09.21, 14:05"},
+		{[]language.Tag{language.French}, `{ DATETIME($date) }`, "12/09/2021"},
+		{[]language.Tag{language.French}, `{ DATETIME($date, dateStyle: "long") }`, "12 septembre 2021"},
+		{[]language.Tag{language.Spanish}, `{ DATETIME($date) }`, "12/9/2021"},
+		{[]language.Tag{language.Spanish}, `{ DATETIME($date, dateStyle: "long") }`, "12 de septiembre de 2021"},
+
+		// Locales without date time symbols fall back to the other locales of the bundle and finally to English
+		{[]language.Tag{language.Japanese, language.German}, `{ DATETIME($date, dateStyle: "medium") }`, "12.09.2021"},
+		{[]language.Tag{language.MustParse("de-AT")}, `{ DATETIME($date, dateStyle: "medium") }`, "12.09.2021"},
+		{[]language.Tag{language.Japanese}, `{ DATETIME($date, dateStyle: "medium") }`, "Sep 12, 2021"},
+	}
+
+	for _, test := range tests {
+		resource, parseErrs := NewResource("date = " + test.source)
+		if len(parseErrs) > 0 {
+			t.Fatalf("could not parse '%s': %v", test.source, parseErrs)
+		}
+		bundle := NewBundle(test.locales[0], test.locales[1:]...)
+		bundle.AddResourceOverriding(resource)
+		bundle.SetUseIsolating(false)
+		result, errs, err := bundle.FormatMessage("date", WithVariable("date", date))
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("'%s' could not be formatted: %v, %v", test.source, err, errs)
+		}
+		if result != test.expected {
+			t.Fatalf("'%s' resolved to %q in %v, expected %q", test.source, result, test.locales, test.expected)
+		}
+	}
+
+	// Invalid arguments are reported as function errors
+	invalid := []string{
+		`{ DATETIME() }`,
+		`{ DATETIME("today") }`,
+		`{ DATETIME($date, timeZone: "Mars/Olympus_Mons") }`,
+		`{ DATETIME($date, weekday: "long", year: "numeric") }`,
+		`{ DATETIME($date, year: "numeric", day: "numeric") }`,
+	}
+	for _, source := range invalid {
+		bundle := newTestBundle(t, "date = "+source)
+		result, errs, err := bundle.FormatMessage("date", WithVariable("date", date))
+		if err != nil {
+			t.Fatalf("'%s' could not be formatted: %v", source, err)
+		}
+		var functionErr *FunctionError
+		if len(errs) != 1 || !errors.As(errs[0], &functionErr) || functionErr.Name != "DATETIME" {
+			t.Fatalf("'%s' did not raise a function error: %v", source, errs)
+		}
+		if result != "{DATETIME()}" {
+			t.Fatalf("'%s' resolved to '%s', expected '{DATETIME()}'", source, result)
+		}
+	}
+}
+
+func TestNumberLocales(t *testing.T) {
+	tests := []struct {
+		locale   language.Tag
+		source   string
+		value    interface{}
+		expected string
+	}{
+		{language.English, `{ $n }`, -1234567.891, "-1,234,567.891"},
+		{language.German, `{ $n }`, -1234567.891, "-1.234.567,891"},
+		{language.MustParse("de-CH"), `{ $n }`, 1234567.5, "1\u2019234\u2019567.5"},
+		// golang.org/x/text ships CLDR 32, which still separates French groups using a no-break space instead of U+202F
+		{language.French, `{ $n }`, 1234567.5, "1\u00a0234\u00a0567,5"},
+		{language.Spanish, `{ $n }`, 1234567.5, "1.234.567,5"},
+		{language.MustParse("hi"), `{ $n }`, 12345678.5, "1,23,45,678.5"},
+		{language.MustParse("bn"), `{ $n }`, 1234.5, "১,২৩৪.৫"},
+		{language.MustParse("fa"), `{ $n }`, 1234.5, "۱٬۲۳۴٫۵"},
+		{language.German, `{ NUMBER($n, style: "percent") }`, 0.25, "25\u00a0%"},
+		{language.French, `{ NUMBER($n, style: "percent") }`, 0.25, "25\u00a0%"},
+		{language.German, `{ NUMBER($n, useGrouping: "false", minimumFractionDigits: 2) }`, 1234.5, "1234,50"},
+	}
+
+	for _, test := range tests {
+		bundle := newTestBundleWithLocale(t, test.locale, "number = "+test.source)
+		result, errs, err := bundle.FormatMessage("number", WithVariable("n", test.value))
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("'%s' could not be formatted: %v, %v", test.source, err, errs)
+		}
+		if result != test.expected {
+			t.Fatalf("'%s' resolved to %q in '%s', expected %q", test.source, result, test.locale, test.expected)
+		}
+	}
+
+	// The raw representation is not localized
+	number := NumberFromInt(-1234567)
+	if raw := number.Raw(); raw != "-1234567" {
+		t.Fatalf("the raw representation is '%s'", raw)
+	}
+	if formatted := number.Format(language.German); formatted != "-1.234.567" {
+		t.Fatalf("the number was formatted as '%s' in German", formatted)
+	}
+}
+
+func TestNumberPrecision(t *testing.T) {
+	tests := []struct {
+		source   string
+		value    interface{}
+		expected string
+	}{
+		{`{ $n }`, float32(16777216), "16,777,216"},
+		{`{ $n }`, int32(16777217), "16,777,217"},
+		{`{ 16777217.000001 }`, 0, "16,777,217.000001"},
+		{`{ $n }`, int64(9007199254740993), "9,007,199,254,740,993"},
+		{`{ $n }`, uint64(math.MaxUint64), "18,446,744,073,709,551,615"},
+		{`{ NUMBER($n, useGrouping: "false") }`, new(big.Int).Lsh(big.NewInt(1), 100), "1267650600228229401496703205376"},
+		{`{ NUMBER($n, maximumFractionDigits: 20) }`, 0.1, "0.1"},
+		{`{ NUMBER($n, maximumFractionDigits: 20) }`, float32(0.1), "0.1"},
+		{`{ $n }`, math.NaN(), "NaN"},
+		{`{ $n }`, math.Inf(1), "∞"},
+		{`{ $n }`, math.Inf(-1), "-∞"},
+		{`{ NUMBER($n, style: "percent") }`, math.Inf(1), "∞%"},
+		{`{ $n }`, new(big.Float).SetInf(true), "-∞"},
+		{"{ $n ->\n [one] one\n *[other] other\n}", math.NaN(), "other"},
+		{"{ $n ->\n [one] one\n *[other] other\n}", math.Inf(1), "other"},
+	}
+
+	for _, test := range tests {
+		bundle := newTestBundle(t, "number = "+test.source)
+		result, errs, err := bundle.FormatMessage("number", WithVariable("n", test.value))
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("'%s' could not be formatted: %v, %v", test.source, err, errs)
+		}
+		if result != test.expected {
+			t.Fatalf("'%s' resolved to %q, expected %q", test.source, result, test.expected)
+		}
+	}
+
+	// Decimal strings are kept exactly, including their fraction digits
+	number, err := ParseNumber("12345678901234567890.1234567890")
+	if err != nil {
+		t.Fatalf("the number could not be parsed: %v", err)
+	}
+	if raw := number.Raw(); raw != "12345678901234567890.1234567890" {
+		t.Fatalf("the number was parsed as '%s'", raw)
+	}
+	if formatted := number.Format(language.English); formatted != "12,345,678,901,234,567,890.1234567890" {
+		t.Fatalf("the number was formatted as '%s'", formatted)
+	}
+
+	// Huge exponents are rejected instead of expanding millions of digits
+	if _, err := ParseNumber("1e999999999"); err == nil {
+		t.Fatal("a number with a huge exponent was parsed")
+	}
+	if number, err := ParseNumber("1.5e-3"); err != nil || number.Raw() != "0.0015" {
+		t.Fatalf("'1.5e-3' was parsed as %v, %v", number, err)
+	}
+
+	// Non-finite numbers are no valid dates
+	bundle := newTestBundle(t, "date = { DATETIME($n) }")
+	if _, errs, _ := bundle.FormatMessage("date", WithVariable("n", math.NaN())); len(errs) != 1 {
+		t.Fatalf("NaN was accepted as a date: %v", errs)
+	}
+}
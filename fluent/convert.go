@@ -0,0 +1,134 @@
+package fluent
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Converter converts a Go value passed as a variable into a Value.
+// It reports false if it does not handle the type of the given value.
+type Converter func(value interface{}) (Value, bool)
+
+// converters holds the converters registered using RegisterConverter
+var (
+	converters      []*Converter
+	convertersMutex sync.RWMutex
+)
+
+// RegisterConverter registers a converter for variables of types the builtin conversion does not handle
+// or handles differently than desired. Converters are consulted in the order they were registered,
+// before any builtin conversion is applied. Calling the returned function unregisters the converter again.
+func RegisterConverter(converter Converter) func() {
+	convertersMutex.Lock()
+	defer convertersMutex.Unlock()
+	registered := &converter
+	converters = append(converters, registered)
+	return func() {
+		convertersMutex.Lock()
+		defer convertersMutex.Unlock()
+		for i, current := range converters {
+			if current == registered {
+				converters = append(converters[:i:i], converters[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// applyConverters returns the result of the first registered converter handling the given value
+func applyConverters(value interface{}) (Value, bool) {
+	convertersMutex.RLock()
+	defer convertersMutex.RUnlock()
+	for _, converter := range converters {
+		if converted, ok := (*converter)(value); ok && converted != nil {
+			return converted, true
+		}
+	}
+	return nil, false
+}
+
+// convertValue converts a Go value passed as a variable into a Value.
+// Besides the registered converters and the values of this package, it handles strings, booleans, numbers (including
+// *big.Int and *big.Float), time.Time, time.Duration, fmt.Stringer, encoding.TextMarshaler and types based on
+// strings, booleans or numbers (e.g. 'type Gender string'). Pointers are dereferenced.
+// The values of this package and values implementing Selectable or Formattable are passed as they are.
+// Like fmt does, String is preferred over the underlying kind, so types based on numbers that implement fmt.Stringer
+// (e.g. enums like time.Month) are converted into strings and select variants by their name instead of plural categories.
+func convertValue(value interface{}) (Value, error) {
+	if value == nil {
+		return nil, fmt.Errorf("nil is no valid value")
+	}
+	if converted, ok := applyConverters(value); ok {
+		return converted, nil
+	}
+
+	// Nil pointers would panic once their methods (e.g. String) get called
+	reflected := reflect.ValueOf(value)
+	if reflected.Kind() == reflect.Ptr && reflected.IsNil() {
+		return nil, fmt.Errorf("nil is no valid value")
+	}
+
+	switch val := value.(type) {
+	case string:
+		return String(val), nil
+	case bool:
+		return String(fmt.Sprint(val)), nil
+	case float32:
+		return &NumberValue{value: decimalFromFloat(float64(val), 32), Options: nil}, nil
+	case float64:
+		return Number(val), nil
+	case time.Time:
+		return DateTime(val), nil
+	case *time.Time:
+		// *time.Time implements fmt.Stringer, so it has to be dereferenced before fmt.Stringer is checked
+		return DateTime(*val), nil
+	case time.Duration:
+		return String(val.String()), nil
+	case *big.Int:
+		parsed, _ := parseDecimal(val.String())
+		return &NumberValue{value: parsed, Options: nil}, nil
+	case *big.Float:
+		if val.IsInf() {
+			return &NumberValue{value: decimalFromFloat(math.Inf(val.Sign()), 64), Options: nil}, nil
+		}
+		parsed, ok := parseDecimal(val.Text('g', -1))
+		if !ok {
+			return nil, fmt.Errorf("'%s' exceeds the supported range of numbers", val.String())
+		}
+		return &NumberValue{value: parsed, Options: nil}, nil
+	case *StringValue, *NumberValue, *DateTimeValue, *NoValue, *ErrorValue, Selectable, Formattable:
+		return value.(Value), nil
+	case fmt.Stringer:
+		// Other values only implementing String are no different from a fmt.Stringer and select variants as strings
+		return String(val.String()), nil
+	case encoding.TextMarshaler:
+		text, err := val.MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		return String(string(text)), nil
+	}
+
+	switch reflected.Kind() {
+	case reflect.String:
+		return String(reflected.String()), nil
+	case reflect.Bool:
+		return String(fmt.Sprint(reflected.Bool())), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return NumberFromInt(reflected.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return NumberFromUint(reflected.Uint()), nil
+	case reflect.Float32:
+		return &NumberValue{value: decimalFromFloat(reflected.Float(), 32), Options: nil}, nil
+	case reflect.Float64:
+		return Number(reflected.Float()), nil
+	case reflect.Ptr:
+		return convertValue(reflected.Elem().Interface())
+	}
+	return nil, fmt.Errorf("values of type '%T' are not supported", value)
+}
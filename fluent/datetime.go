@@ -0,0 +1,353 @@
+package fluent
+
+import (
+	"errors"
+	"golang.org/x/text/language"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateTimeFormatOptions holds the subset of the options of JavaScript's Intl.DateTimeFormat that are used to format a DateTimeValue.
+// Empty options are not set. If neither a style nor a single component option is set, the date is formatted using numeric year, month and day.
+// DATETIME rejects combinations of components no pattern exists for (e.g. a weekday and a year only); if they are set
+// on a DateTimeValue directly, numeric year, month and day are formatted instead of the date components.
+type DateTimeFormatOptions struct {
+	DateStyle    string // "full", "long", "medium" or "short"
+	TimeStyle    string // "full", "long", "medium" or "short"
+	Weekday      string // "long", "short" or "narrow"
+	Year         string // "numeric" or "2-digit"
+	Month        string // "numeric", "2-digit", "long", "short" or "narrow"
+	Day          string // "numeric" or "2-digit"
+	Hour         string // "numeric" or "2-digit"
+	Minute       string // "numeric" or "2-digit"
+	Second       string // "numeric" or "2-digit"
+	Hour12       string // "true" or "false"; the default of the locale is used if empty
+	TimeZone     string // An IANA time zone name like "Europe/Berlin"; the location of the time is used if empty or invalid
+	TimeZoneName string // "long" or "short"; both use the abbreviation of the time zone
+}
+
+// hasComponents checks whether any single date or time component is requested explicitly
+func (options *DateTimeFormatOptions) hasComponents() bool {
+	return options.Weekday != "" || options.Year != "" || options.Month != "" || options.Day != "" ||
+		options.Hour != "" || options.Minute != "" || options.Second != "" || options.TimeZoneName != ""
+}
+
+// formatDateTime formats a time according to the given options and the conventions of the first of the given locales
+// date time symbols are available for
+func formatDateTime(value time.Time, options *DateTimeFormatOptions, locales ...language.Tag) string {
+	if options == nil {
+		options = &DateTimeFormatOptions{}
+	}
+	if options.TimeZone != "" {
+		if location, err := time.LoadLocation(options.TimeZone); err == nil {
+			value = value.In(location)
+		}
+	}
+
+	symbols := lookupDateTimeSymbols(locales...)
+	hour12 := symbols.hour12
+	if options.Hour12 != "" {
+		hour12 = options.Hour12 == "true"
+	}
+
+	var pattern string
+	if options.DateStyle != "" || options.TimeStyle != "" {
+		pattern = symbols.stylePattern(options.DateStyle, options.TimeStyle, hour12)
+	} else {
+		if !options.hasComponents() {
+			options = &DateTimeFormatOptions{Year: "numeric", Month: "numeric", Day: "numeric"}
+		}
+		pattern, _ = symbols.componentPattern(options, hour12)
+	}
+
+	return symbols.format(value, pattern)
+}
+
+// stylePattern builds the CLDR pattern for the given date and time styles
+func (symbols *dateTimeSymbols) stylePattern(dateStyle, timeStyle string, hour12 bool) string {
+	datePattern := symbols.dateStyles[dateStyle]
+	timePattern := symbols.timeStyles[timeStyle]
+
+	// If the requested hour cycle differs from the one of the locale, build the time pattern out of the single components
+	if timePattern != "" && hour12 != symbols.hour12 {
+		skeleton := "Hms"
+		if hour12 {
+			skeleton = "hms"
+		}
+		if timeStyle == "short" {
+			skeleton = skeleton[:2]
+		}
+		timePattern = symbols.availableFormats[skeleton]
+		if timeStyle == "full" || timeStyle == "long" {
+			timePattern += " z"
+		}
+	}
+
+	switch {
+	case datePattern == "":
+		return timePattern
+	case timePattern == "":
+		return datePattern
+	default:
+		joiner := symbols.dateTimeJoiners[dateStyle]
+		return strings.Replace(strings.Replace(joiner, "{1}", datePattern, 1), "{0}", timePattern, 1)
+	}
+}
+
+// validate checks whether a pattern exists for the requested components.
+// Every locale provides the same skeletons, so the check does not depend on the locale.
+func (options *DateTimeFormatOptions) validate() error {
+	if options.DateStyle != "" || options.TimeStyle != "" || !options.hasComponents() {
+		return nil
+	}
+	if _, ok := dateTimeData[0].componentPattern(options, false); !ok {
+		return errors.New("no date time pattern exists for the requested combination of components")
+	}
+	return nil
+}
+
+// componentPattern builds the CLDR pattern for the explicitly requested components.
+// If no pattern exists for the requested date components, the pattern of numeric year, month and day is used
+// and false is returned.
+func (symbols *dateTimeSymbols) componentPattern(options *DateTimeFormatOptions, hour12 bool) (string, bool) {
+	ok := true
+	// Build the skeleton of the date part and look up the matching pattern of the locale
+	datePattern := ""
+	if options.Year != "" || options.Month != "" || options.Day != "" || options.Weekday != "" {
+		month := ""
+		switch options.Month {
+		case "numeric", "2-digit":
+			month = "M"
+		case "short", "narrow":
+			month = "MMM"
+		case "long":
+			month = "MMMM"
+		}
+
+		skeleton := ""
+		if options.Year != "" {
+			skeleton += "y"
+		}
+		skeleton += month
+		if options.Weekday != "" {
+			skeleton += "E"
+		}
+		if options.Day != "" {
+			skeleton += "d"
+		}
+
+		pattern, found := symbols.availableFormats[skeleton]
+		if !found && month == "MMMM" {
+			pattern, found = symbols.availableFormats[strings.Replace(skeleton, "MMMM", "MMM", 1)]
+			pattern = replaceField(pattern, 'M', 3, "MMMM")
+		}
+		if !found {
+			pattern, ok = symbols.availableFormats["yMd"], false
+		}
+
+		// Adjust the widths of the fields to the requested ones
+		if options.Year == "2-digit" {
+			pattern = replaceField(pattern, 'y', 1, "yy")
+		}
+		switch options.Month {
+		case "2-digit":
+			pattern = replaceField(pattern, 'M', 1, "MM")
+		case "narrow":
+			pattern = replaceField(pattern, 'M', 3, "MMMMM")
+		}
+		if options.Day == "2-digit" {
+			pattern = replaceField(pattern, 'd', 1, "dd")
+		}
+		switch options.Weekday {
+		case "long":
+			pattern = replaceField(pattern, 'E', 3, "EEEE")
+		case "narrow":
+			pattern = replaceField(pattern, 'E', 3, "EEEEE")
+		}
+		datePattern = pattern
+	}
+
+	// Build the skeleton of the time part and look up the matching pattern of the locale
+	timePattern := ""
+	switch {
+	case options.Hour != "":
+		skeleton := "H"
+		if hour12 {
+			skeleton = "h"
+		}
+		if options.Minute != "" || options.Second != "" {
+			skeleton += "m"
+		}
+		if options.Second != "" {
+			skeleton += "s"
+		}
+
+		timePattern = symbols.availableFormats[skeleton]
+		if options.Hour == "2-digit" {
+			timePattern = replaceField(timePattern, 'h', 1, "hh")
+		}
+	case options.Minute != "" && options.Second != "":
+		timePattern = "mm:ss"
+	case options.Minute != "":
+		timePattern = "m"
+		if options.Minute == "2-digit" {
+			timePattern = "mm"
+		}
+	case options.Second != "":
+		timePattern = "s"
+		if options.Second == "2-digit" {
+			timePattern = "ss"
+		}
+	}
+
+	// Like Intl.DateTimeFormat, a time zone name without any other component is appended to the default date
+	if options.TimeZoneName != "" {
+		if timePattern == "" {
+			if datePattern == "" {
+				datePattern = symbols.availableFormats["yMd"]
+			}
+			timePattern = "z"
+		} else {
+			timePattern += " z"
+		}
+	}
+
+	switch {
+	case datePattern == "":
+		return timePattern, ok
+	case timePattern == "":
+		return datePattern, ok
+	default:
+		joiner := symbols.dateTimeJoiners["medium"]
+		return strings.Replace(strings.Replace(joiner, "{1}", datePattern, 1), "{0}", timePattern, 1), ok
+	}
+}
+
+// replaceField replaces the first unquoted field made of exactly width repetitions of the given letter
+func replaceField(pattern string, letter rune, width int, replacement string) string {
+	runes := []rune(pattern)
+	quoted := false
+	for i := 0; i < len(runes); {
+		if runes[i] == '\'' {
+			quoted = !quoted
+			i++
+			continue
+		}
+		if quoted || runes[i] != letter {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && runes[j] == letter {
+			j++
+		}
+		if j-i == width {
+			return string(runes[:i]) + replacement + string(runes[j:])
+		}
+		i = j
+	}
+	return pattern
+}
+
+// format formats a time using a CLDR date time pattern
+func (symbols *dateTimeSymbols) format(value time.Time, pattern string) string {
+	var builder strings.Builder
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); {
+		char := runes[i]
+
+		// Text enclosed in single quotes is copied literally; two single quotes represent a literal single quote
+		if char == '\'' {
+			if i+1 < len(runes) && runes[i+1] == '\'' {
+				builder.WriteRune('\'')
+				i += 2
+				continue
+			}
+			i++
+			for i < len(runes) && runes[i] != '\'' {
+				builder.WriteRune(runes[i])
+				i++
+			}
+			i++
+			continue
+		}
+
+		if !(char >= 'a' && char <= 'z') && !(char >= 'A' && char <= 'Z') {
+			builder.WriteRune(char)
+			i++
+			continue
+		}
+
+		width := 0
+		for i < len(runes) && runes[i] == char {
+			width++
+			i++
+		}
+		builder.WriteString(symbols.formatField(value, char, width))
+	}
+	return builder.String()
+}
+
+// formatField formats a single field of a CLDR date time pattern
+func (symbols *dateTimeSymbols) formatField(value time.Time, field rune, width int) string {
+	switch field {
+	case 'y':
+		if width == 2 {
+			return padNumber(value.Year()%100, 2)
+		}
+		return strconv.Itoa(value.Year())
+	case 'M', 'L':
+		month := int(value.Month()) - 1
+		switch {
+		case width >= 5:
+			return string([]rune(symbols.months[month])[:1])
+		case width == 4:
+			return symbols.months[month]
+		case width == 3:
+			return symbols.monthsShort[month]
+		default:
+			return padNumber(month+1, width)
+		}
+	case 'd':
+		return padNumber(value.Day(), width)
+	case 'E', 'c':
+		weekday := int(value.Weekday())
+		switch {
+		case width >= 5:
+			return string([]rune(symbols.weekdays[weekday])[:1])
+		case width == 4:
+			return symbols.weekdays[weekday]
+		default:
+			return symbols.weekdaysShort[weekday]
+		}
+	case 'h':
+		hour := value.Hour() % 12
+		if hour == 0 {
+			hour = 12
+		}
+		return padNumber(hour, width)
+	case 'H':
+		return padNumber(value.Hour(), width)
+	case 'm':
+		return padNumber(value.Minute(), width)
+	case 's':
+		return padNumber(value.Second(), width)
+	case 'a':
+		return symbols.dayPeriods[value.Hour()/12]
+	case 'z':
+		name, _ := value.Zone()
+		return name
+	default:
+		return ""
+	}
+}
+
+// padNumber formats a number with at least the given amount of digits
+func padNumber(number, width int) string {
+	formatted := strconv.Itoa(number)
+	for len(formatted) < width {
+		formatted = "0" + formatted
+	}
+	return formatted
+}
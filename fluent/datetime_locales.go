@@ -0,0 +1,315 @@
+package fluent
+
+import "golang.org/x/text/language"
+
+// dateTimeSymbols holds the CLDR data required to format dates and times for a single locale
+type dateTimeSymbols struct {
+	months           [12]string
+	monthsShort      [12]string
+	weekdays         [7]string // Starting on Sunday like time.Weekday
+	weekdaysShort    [7]string // Starting on Sunday like time.Weekday
+	dayPeriods       [2]string
+	hour12           bool
+	dateStyles       map[string]string
+	timeStyles       map[string]string
+	dateTimeJoiners  map[string]string
+	availableFormats map[string]string
+}
+
+// dateTimeLocales holds the date time symbols of all locales supported out of the box.
+// The first locale is used as the fallback if no other locale matches.
+var dateTimeLocales = []language.Tag{
+	language.AmericanEnglish,
+	language.BritishEnglish,
+	language.German,
+	language.French,
+	language.Spanish,
+}
+
+// dateTimeLocaleMatcher matches arbitrary tags against the supported date time locales
+var dateTimeLocaleMatcher = language.NewMatcher(dateTimeLocales)
+
+// lookupDateTimeSymbols returns the date time symbols that match the given locales best, preferring earlier locales.
+// If none of the locales is supported, the symbols of the first supported locale (American English) are returned.
+func lookupDateTimeSymbols(locales ...language.Tag) *dateTimeSymbols {
+	_, index, confidence := dateTimeLocaleMatcher.Match(locales...)
+	if confidence == language.No {
+		return dateTimeData[0]
+	}
+	return dateTimeData[index]
+}
+
+// dateTimeData holds the date time symbols in the order of dateTimeLocales
+var dateTimeData = []*dateTimeSymbols{
+	// en-US
+	{
+		months:        [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		monthsShort:   [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+		weekdays:      [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		weekdaysShort: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		dayPeriods:    [2]string{"AM", "PM"},
+		hour12:        true,
+		dateStyles: map[string]string{
+			"full":   "EEEE, MMMM d, y",
+			"long":   "MMMM d, y",
+			"medium": "MMM d, y",
+			"short":  "M/d/yy",
+		},
+		timeStyles: map[string]string{
+			"full":   "h:mm:ss\u202fa z",
+			"long":   "h:mm:ss\u202fa z",
+			"medium": "h:mm:ss\u202fa",
+			"short":  "h:mm\u202fa",
+		},
+		dateTimeJoiners: map[string]string{
+			"full":   "{1} 'at' {0}",
+			"long":   "{1} 'at' {0}",
+			"medium": "{1}, {0}",
+			"short":  "{1}, {0}",
+		},
+		availableFormats: map[string]string{
+			"y":      "y",
+			"M":      "M",
+			"MMM":    "MMM",
+			"MMMM":   "MMMM",
+			"d":      "d",
+			"E":      "EEE",
+			"Ed":     "d EEE",
+			"Md":     "M/d",
+			"MEd":    "EEE, M/d",
+			"MMMd":   "MMM d",
+			"MMMEd":  "EEE, MMM d",
+			"MMMMd":  "MMMM d",
+			"yM":     "M/y",
+			"yMd":    "M/d/y",
+			"yMEd":   "EEE, M/d/y",
+			"yMMM":   "MMM y",
+			"yMMMd":  "MMM d, y",
+			"yMMMEd": "EEE, MMM d, y",
+			"yMMMM":  "MMMM y",
+			"h":      "h\u202fa",
+			"hm":     "h:mm\u202fa",
+			"hms":    "h:mm:ss\u202fa",
+			"H":      "HH",
+			"Hm":     "HH:mm",
+			"Hms":    "HH:mm:ss",
+		},
+	},
+	// en-GB
+	{
+		months:        [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+		monthsShort:   [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sept", "Oct", "Nov", "Dec"},
+		weekdays:      [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+		weekdaysShort: [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+		dayPeriods:    [2]string{"am", "pm"},
+		hour12:        false,
+		dateStyles: map[string]string{
+			"full":   "EEEE d MMMM y",
+			"long":   "d MMMM y",
+			"medium": "d MMM y",
+			"short":  "dd/MM/y",
+		},
+		timeStyles: map[string]string{
+			"full":   "HH:mm:ss z",
+			"long":   "HH:mm:ss z",
+			"medium": "HH:mm:ss",
+			"short":  "HH:mm",
+		},
+		dateTimeJoiners: map[string]string{
+			"full":   "{1} 'at' {0}",
+			"long":   "{1} 'at' {0}",
+			"medium": "{1}, {0}",
+			"short":  "{1}, {0}",
+		},
+		availableFormats: map[string]string{
+			"y":      "y",
+			"M":      "M",
+			"MMM":    "MMM",
+			"MMMM":   "MMMM",
+			"d":      "d",
+			"E":      "EEE",
+			"Ed":     "EEE d",
+			"Md":     "dd/MM",
+			"MEd":    "EEE dd/MM",
+			"MMMd":   "d MMM",
+			"MMMEd":  "EEE d MMM",
+			"MMMMd":  "d MMMM",
+			"yM":     "MM/y",
+			"yMd":    "dd/MM/y",
+			"yMEd":   "EEE, dd/MM/y",
+			"yMMM":   "MMM y",
+			"yMMMd":  "d MMM y",
+			"yMMMEd": "EEE, d MMM y",
+			"yMMMM":  "MMMM y",
+			"h":      "h\u202fa",
+			"hm":     "h:mm\u202fa",
+			"hms":    "h:mm:ss\u202fa",
+			"H":      "HH",
+			"Hm":     "HH:mm",
+			"Hms":    "HH:mm:ss",
+		},
+	},
+	// de
+	{
+		months:        [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		monthsShort:   [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		weekdays:      [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		weekdaysShort: [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+		dayPeriods:    [2]string{"AM", "PM"},
+		hour12:        false,
+		dateStyles: map[string]string{
+			"full":   "EEEE, d. MMMM y",
+			"long":   "d. MMMM y",
+			"medium": "dd.MM.y",
+			"short":  "dd.MM.yy",
+		},
+		timeStyles: map[string]string{
+			"full":   "HH:mm:ss z",
+			"long":   "HH:mm:ss z",
+			"medium": "HH:mm:ss",
+			"short":  "HH:mm",
+		},
+		dateTimeJoiners: map[string]string{
+			"full":   "{1} 'um' {0}",
+			"long":   "{1} 'um' {0}",
+			"medium": "{1}, {0}",
+			"short":  "{1}, {0}",
+		},
+		availableFormats: map[string]string{
+			"y":      "y",
+			"M":      "M",
+			"MMM":    "MMM",
+			"MMMM":   "MMMM",
+			"d":      "d",
+			"E":      "EEE",
+			"Ed":     "EEE, d.",
+			"Md":     "d.M.",
+			"MEd":    "EEE, d.M.",
+			"MMMd":   "d. MMM",
+			"MMMEd":  "EEE, d. MMM",
+			"MMMMd":  "d. MMMM",
+			"yM":     "M/y",
+			"yMd":    "d.M.y",
+			"yMEd":   "EEE, d.M.y",
+			"yMMM":   "MMM y",
+			"yMMMd":  "d. MMM y",
+			"yMMMEd": "EEE, d. MMM y",
+			"yMMMM":  "MMMM y",
+			"h":      "h 'Uhr' a",
+			"hm":     "h:mm\u202fa",
+			"hms":    "h:mm:ss\u202fa",
+			"H":      "HH 'Uhr'",
+			"Hm":     "HH:mm",
+			"Hms":    "HH:mm:ss",
+		},
+	},
+	// fr
+	{
+		months:        [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		monthsShort:   [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		weekdays:      [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		weekdaysShort: [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+		dayPeriods:    [2]string{"AM", "PM"},
+		hour12:        false,
+		dateStyles: map[string]string{
+			"full":   "EEEE d MMMM y",
+			"long":   "d MMMM y",
+			"medium": "d MMM y",
+			"short":  "dd/MM/y",
+		},
+		timeStyles: map[string]string{
+			"full":   "HH:mm:ss z",
+			"long":   "HH:mm:ss z",
+			"medium": "HH:mm:ss",
+			"short":  "HH:mm",
+		},
+		dateTimeJoiners: map[string]string{
+			"full":   "{1} 'à' {0}",
+			"long":   "{1} 'à' {0}",
+			"medium": "{1} {0}",
+			"short":  "{1} {0}",
+		},
+		availableFormats: map[string]string{
+			"y":      "y",
+			"M":      "M",
+			"MMM":    "MMM",
+			"MMMM":   "MMMM",
+			"d":      "d",
+			"E":      "EEE",
+			"Ed":     "EEE d",
+			"Md":     "dd/MM",
+			"MEd":    "EEE dd/MM",
+			"MMMd":   "d MMM",
+			"MMMEd":  "EEE d MMM",
+			"MMMMd":  "d MMMM",
+			"yM":     "MM/y",
+			"yMd":    "dd/MM/y",
+			"yMEd":   "EEE dd/MM/y",
+			"yMMM":   "MMM y",
+			"yMMMd":  "d MMM y",
+			"yMMMEd": "EEE d MMM y",
+			"yMMMM":  "MMMM y",
+			"h":      "h\u202fa",
+			"hm":     "h:mm\u202fa",
+			"hms":    "h:mm:ss\u202fa",
+			"H":      "HH 'h'",
+			"Hm":     "HH:mm",
+			"Hms":    "HH:mm:ss",
+		},
+	},
+	// es
+	{
+		months:        [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		monthsShort:   [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		weekdays:      [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		weekdaysShort: [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+		dayPeriods:    [2]string{"a.\u00a0m.", "p.\u00a0m."},
+		hour12:        false,
+		dateStyles: map[string]string{
+			"full":   "EEEE, d 'de' MMMM 'de' y",
+			"long":   "d 'de' MMMM 'de' y",
+			"medium": "d MMM y",
+			"short":  "d/M/yy",
+		},
+		timeStyles: map[string]string{
+			"full":   "H:mm:ss z",
+			"long":   "H:mm:ss z",
+			"medium": "H:mm:ss",
+			"short":  "H:mm",
+		},
+		dateTimeJoiners: map[string]string{
+			"full":   "{1}, {0}",
+			"long":   "{1}, {0}",
+			"medium": "{1}, {0}",
+			"short":  "{1}, {0}",
+		},
+		availableFormats: map[string]string{
+			"y":      "y",
+			"M":      "M",
+			"MMM":    "MMM",
+			"MMMM":   "MMMM",
+			"d":      "d",
+			"E":      "EEE",
+			"Ed":     "EEE d",
+			"Md":     "d/M",
+			"MEd":    "EEE, d/M",
+			"MMMd":   "d MMM",
+			"MMMEd":  "EEE, d MMM",
+			"MMMMd":  "d 'de' MMMM",
+			"yM":     "M/y",
+			"yMd":    "d/M/y",
+			"yMEd":   "EEE, d/M/y",
+			"yMMM":   "MMM y",
+			"yMMMd":  "d MMM y",
+			"yMMMEd": "EEE, d MMM y",
+			"yMMMM":  "MMMM 'de' y",
+			"yMMMMd": "d 'de' MMMM 'de' y",
+			"h":      "h\u202fa",
+			"hm":     "h:mm\u202fa",
+			"hms":    "h:mm:ss\u202fa",
+			"H":      "H",
+			"Hm":     "H:mm",
+			"Hms":    "H:mm:ss",
+		},
+	},
+}
@@ -0,0 +1,156 @@
+package fluent
+
+import (
+	"errors"
+	"fmt"
+	"github.com/lus/fluent.go/fluent/parser/ast"
+	"strings"
+)
+
+// ErrTooManyPlaceables is raised if formatting a message would expand more placeables than the bundle allows.
+// It aborts the whole formatting process.
+var ErrTooManyPlaceables = errors.New("too many placeables expanded")
+
+// ErrPlaceableTooLong is raised if a single placeable resolves to more characters than the bundle allows.
+// It aborts the formatting of the message.
+var ErrPlaceableTooLong = errors.New("too many characters in placeable")
+
+// NoValueError is raised if the value of a message that consists of attributes only (or of a term without a value) is requested
+type NoValueError struct {
+	ErrorContext
+	ID string // The ID of the message or term; the IDs of terms start with a '-'
+}
+
+// Error turns the error into a string
+func (err *NoValueError) Error() string {
+	if strings.HasPrefix(err.ID, "-") {
+		return fmt.Sprintf("term '%s' has no value", err.ID)
+	}
+	return fmt.Sprintf("message '%s' has no value", err.ID)
+}
+
+// ErrorContext describes where an error was raised while formatting a message.
+// It is embedded into the errors returned by the resolver; errors returned by the bundle for missing messages leave it empty.
+type ErrorContext struct {
+	MessageID string   // The ID of the formatted message, e.g. 'login' or 'login.placeholder' if an attribute was formatted
+	Node      ast.Node // The AST node that caused the error
+	Span      [2]uint  // The span of the node
+}
+
+// UnknownVariableError is raised if a variable is referenced that was not passed to the formatting call
+type UnknownVariableError struct {
+	ErrorContext
+	Name string
+}
+
+// Error turns the error into a string
+func (err *UnknownVariableError) Error() string {
+	return fmt.Sprintf("unknown variable '$%s'", err.Name)
+}
+
+// InvalidVariableError is raised if a variable is referenced whose value could not be converted into a Value
+type InvalidVariableError struct {
+	ErrorContext
+	Name string
+	Err  error
+}
+
+// Error turns the error into a string
+func (err *InvalidVariableError) Error() string {
+	return fmt.Sprintf("invalid variable '$%s': %s", err.Name, err.Err)
+}
+
+// Unwrap returns the error that occurred while converting the value
+func (err *InvalidVariableError) Unwrap() error {
+	return err.Err
+}
+
+// UnknownMessageError is raised if a message or message attribute is referenced or formatted that does not exist
+type UnknownMessageError struct {
+	ErrorContext
+	ID        string
+	Attribute string // The name of the missing attribute; empty if the message itself is missing
+}
+
+// Error turns the error into a string
+func (err *UnknownMessageError) Error() string {
+	if err.Attribute != "" {
+		return fmt.Sprintf("unknown message attribute '%s.%s'", err.ID, err.Attribute)
+	}
+	return fmt.Sprintf("unknown message '%s'", err.ID)
+}
+
+// UnknownTermError is raised if a term or term attribute is referenced that does not exist
+type UnknownTermError struct {
+	ErrorContext
+	ID        string // The ID of the term without the leading '-'
+	Attribute string // The name of the missing attribute; empty if the term itself is missing
+}
+
+// Error turns the error into a string
+func (err *UnknownTermError) Error() string {
+	if err.Attribute != "" {
+		return fmt.Sprintf("unknown term attribute '-%s.%s'", err.ID, err.Attribute)
+	}
+	return fmt.Sprintf("unknown term '-%s'", err.ID)
+}
+
+// UnknownFunctionError is raised if a function is called that is neither registered in the bundle nor passed to the formatting call
+type UnknownFunctionError struct {
+	ErrorContext
+	Name string
+}
+
+// Error turns the error into a string
+func (err *UnknownFunctionError) Error() string {
+	return fmt.Sprintf("unknown function '%s'", err.Name)
+}
+
+// FunctionError is raised if a function could not produce a value, e.g. because of invalid arguments
+type FunctionError struct {
+	ErrorContext
+	Name string
+	Err  error
+}
+
+// Error turns the error into a string
+func (err *FunctionError) Error() string {
+	return fmt.Sprintf("function '%s' failed: %s", err.Name, err.Err)
+}
+
+// Unwrap returns the error reported by the function
+func (err *FunctionError) Unwrap() error {
+	return err.Err
+}
+
+// CyclicReferenceError is raised if a message, term or attribute references itself directly or indirectly
+type CyclicReferenceError struct {
+	ErrorContext
+	Chain []string // The names of the entries forming the cycle, e.g. ['a', 'b', 'a']
+}
+
+// Error turns the error into a string
+func (err *CyclicReferenceError) Error() string {
+	return fmt.Sprintf("cyclic reference: %s", strings.Join(err.Chain, " -> "))
+}
+
+// NoDefaultVariantError is raised if no variant of a select expression matches and none is marked as the default one
+type NoDefaultVariantError struct {
+	ErrorContext
+}
+
+// Error turns the error into a string
+func (err *NoDefaultVariantError) Error() string {
+	return "no default variant specified"
+}
+
+// InvalidNumberError is raised if a number literal can not be parsed
+type InvalidNumberError struct {
+	ErrorContext
+	Value string
+}
+
+// Error turns the error into a string
+func (err *InvalidNumberError) Error() string {
+	return fmt.Sprintf("invalid number literal '%s'", err.Value)
+}
@@ -0,0 +1,132 @@
+// Package fluenthttp provides a net/http middleware that negotiates the locale of a request
+// and attaches the matching bundles to its context.
+package fluenthttp
+
+import (
+	"context"
+	"github.com/lus/fluent.go/fluent"
+	"github.com/lus/fluent.go/fluent/langneg"
+	"golang.org/x/text/language"
+	"net/http"
+	"sort"
+)
+
+// Source extracts the locales requested by the client out of a request, ordered by preference
+type Source func(request *http.Request) []language.Tag
+
+// AcceptLanguage creates a source reading the locales of the Accept-Language header
+func AcceptLanguage() Source {
+	return func(request *http.Request) []language.Tag {
+		return langneg.ParseAcceptLanguage(request.Header.Get("Accept-Language"))
+	}
+}
+
+// Cookie creates a source reading a single locale from the cookie with the given name
+func Cookie(name string) Source {
+	return func(request *http.Request) []language.Tag {
+		cookie, err := request.Cookie(name)
+		if err != nil {
+			return nil
+		}
+		return parseLocale(cookie.Value)
+	}
+}
+
+// QueryParameter creates a source reading a single locale from the query parameter with the given name
+func QueryParameter(name string) Source {
+	return func(request *http.Request) []language.Tag {
+		return parseLocale(request.URL.Query().Get(name))
+	}
+}
+
+// DefaultSources are used by Middleware if no sources are passed.
+// The 'lang' query parameter takes precedence over the 'lang' cookie, which takes precedence over the Accept-Language header.
+func DefaultSources() []Source {
+	return []Source{QueryParameter("lang"), Cookie("lang"), AcceptLanguage()}
+}
+
+// parseLocale parses a single locale; invalid locales result in an empty slice
+func parseLocale(raw string) []language.Tag {
+	if raw == "" {
+		return nil
+	}
+	tag, err := language.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	return []language.Tag{tag}
+}
+
+// contextKey is the type of the key the localization is stored with in the request context
+type contextKey struct{}
+
+// NewContext returns a copy of the parent context holding the given localization
+func NewContext(parent context.Context, localization *fluent.Localization) context.Context {
+	return context.WithValue(parent, contextKey{}, localization)
+}
+
+// LocalizationFromContext returns the localization holding the negotiated bundles of a request in the order of preference.
+// It returns nil if the context does not hold a localization.
+func LocalizationFromContext(ctx context.Context) *fluent.Localization {
+	localization, _ := ctx.Value(contextKey{}).(*fluent.Localization)
+	return localization
+}
+
+// FromContext returns the bundle of the most preferred negotiated locale of a request.
+// It returns nil if the context does not hold a localization or if no bundle could be negotiated.
+func FromContext(ctx context.Context) *fluent.Bundle {
+	localization := LocalizationFromContext(ctx)
+	if localization == nil {
+		return nil
+	}
+	bundles := localization.Bundles()
+	if len(bundles) == 0 {
+		return nil
+	}
+	return bundles[0]
+}
+
+// Middleware creates a middleware that negotiates the locales of every request and attaches the matching bundles to its
+// context as a fluent.Localization; use FromContext or LocalizationFromContext to access them.
+// The locales requested by the sources (DefaultSources if none are passed) are negotiated against the locales of the
+// given bundles using the filtering strategy of langneg.NegotiateLanguages. The bundle of the default locale is always
+// used as the last fallback. The Content-Language header of the response is set to the most preferred negotiated locale
+// and 'Accept-Language, Cookie' is added to its Vary header, so shared caches do not serve one locale to everyone.
+func Middleware(bundles map[language.Tag]*fluent.Bundle, defaultLocale language.Tag, sources ...Source) func(http.Handler) http.Handler {
+	if len(sources) == 0 {
+		sources = DefaultSources()
+	}
+
+	// Sort the available locales to make the negotiation deterministic
+	available := make([]language.Tag, 0, len(bundles))
+	for locale := range bundles {
+		available = append(available, locale)
+	}
+	sort.Slice(available, func(i, j int) bool {
+		return available[i].String() < available[j].String()
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			var requested []language.Tag
+			for _, source := range sources {
+				requested = append(requested, source(request)...)
+			}
+
+			negotiated := langneg.NegotiateLanguages(requested, available, langneg.Filtering, defaultLocale)
+			chain := make([]*fluent.Bundle, 0, len(negotiated))
+			for _, locale := range negotiated {
+				if bundle := bundles[locale]; bundle != nil {
+					chain = append(chain, bundle)
+				}
+			}
+			if len(chain) > 0 {
+				writer.Header().Set("Content-Language", chain[0].Locales()[0].String())
+			}
+			// The response depends on the request headers the default sources read; the query is part of the URL anyway
+			writer.Header().Add("Vary", "Accept-Language, Cookie")
+
+			next.ServeHTTP(writer, request.WithContext(NewContext(request.Context(), fluent.NewLocalization(chain...))))
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package fluenthttp
+
+import (
+	"github.com/lus/fluent.go/fluent"
+	"golang.org/x/text/language"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestBundle creates a bundle with a single 'hello' message
+func newTestBundle(t *testing.T, locale language.Tag, hello string) *fluent.Bundle {
+	resource, errs := fluent.NewResource("hello = " + hello)
+	if len(errs) > 0 {
+		t.Fatalf("resource could not be parsed: %v", errs)
+	}
+	bundle := fluent.NewBundle(locale)
+	bundle.AddResourceOverriding(resource)
+	return bundle
+}
+
+func TestMiddleware(t *testing.T) {
+	bundles := map[language.Tag]*fluent.Bundle{
+		language.English:            newTestBundle(t, language.English, "Hello"),
+		language.German:             newTestBundle(t, language.German, "Hallo"),
+		language.MustParse("fr-FR"): newTestBundle(t, language.MustParse("fr-FR"), "Bonjour"),
+	}
+	handler := Middleware(bundles, language.English)(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		bundle := FromContext(request.Context())
+		if bundle == nil {
+			t.Fatalf("no bundle attached to the request context")
+		}
+		result, _, err := bundle.FormatMessage("hello")
+		if err != nil {
+			t.Fatalf("message could not be formatted: %v", err)
+		}
+		writer.Write([]byte(result))
+	}))
+
+	tests := []struct {
+		target         string
+		cookie         string
+		acceptLanguage string
+		expected       string
+		locale         string
+	}{
+		{"/", "", "", "Hello", "en"},
+		{"/", "", "de-DE,de;q=0.9,en;q=0.8", "Hallo", "de"},
+		{"/", "", "fr;q=0.5,de;q=0.7", "Hallo", "de"},
+		{"/", "", "fr-CA", "Bonjour", "fr-FR"},
+		{"/", "", "ja", "Hello", "en"},
+		{"/", "fr", "de", "Bonjour", "fr-FR"},
+		{"/?lang=de", "fr", "en", "Hallo", "de"},
+		{"/?lang=invalid!", "", "fr", "Bonjour", "fr-FR"},
+	}
+	for _, test := range tests {
+		request := httptest.NewRequest(http.MethodGet, test.target, nil)
+		if test.cookie != "" {
+			request.AddCookie(&http.Cookie{Name: "lang", Value: test.cookie})
+		}
+		if test.acceptLanguage != "" {
+			request.Header.Set("Accept-Language", test.acceptLanguage)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+
+		if body := recorder.Body.String(); body != test.expected {
+			t.Fatalf("request '%s' (cookie '%s', Accept-Language '%s') was answered with '%s' instead of '%s'",
+				test.target, test.cookie, test.acceptLanguage, body, test.expected)
+		}
+		if locale := recorder.Header().Get("Content-Language"); locale != test.locale {
+			t.Fatalf("request '%s' has the Content-Language '%s' instead of '%s'", test.target, locale, test.locale)
+		}
+		if vary := recorder.Header().Get("Vary"); vary != "Accept-Language, Cookie" {
+			t.Fatalf("request '%s' has the Vary header '%s'", test.target, vary)
+		}
+	}
+}
+
+func TestMiddlewareSources(t *testing.T) {
+	bundles := map[language.Tag]*fluent.Bundle{
+		language.English: newTestBundle(t, language.English, "Hello"),
+		language.German:  newTestBundle(t, language.German, "Hallo"),
+	}
+	var localization *fluent.Localization
+	handler := Middleware(bundles, language.English, Cookie("locale"))(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		localization = LocalizationFromContext(request.Context())
+	}))
+
+	request := httptest.NewRequest(http.MethodGet, "/?lang=de", nil)
+	request.Header.Set("Accept-Language", "de")
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	if bundles := localization.Bundles(); len(bundles) != 1 || bundles[0].Locales()[0] != language.English {
+		t.Fatalf("sources that were not configured were used")
+	}
+
+	request = httptest.NewRequest(http.MethodGet, "/", nil)
+	request.AddCookie(&http.Cookie{Name: "locale", Value: "de"})
+	handler.ServeHTTP(httptest.NewRecorder(), request)
+	if bundles := localization.Bundles(); len(bundles) != 2 || bundles[0].Locales()[0] != language.German {
+		t.Fatalf("the German bundle was not negotiated first")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	if FromContext(request.Context()) != nil || LocalizationFromContext(request.Context()) != nil {
+		t.Fatalf("a localization was found in an empty context")
+	}
+	if FromContext(NewContext(request.Context(), fluent.NewLocalization())) != nil {
+		t.Fatalf("a bundle was found in an empty localization")
+	}
+}
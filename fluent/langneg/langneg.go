@@ -0,0 +1,256 @@
+// Package langneg implements language negotiation like @fluent/langneg does.
+// It picks the locales to use out of the available ones based on the locales requested by a user,
+// e.g. using the Accept-Language header of an HTTP request.
+package langneg
+
+import (
+	"golang.org/x/text/language"
+	"sort"
+	"strings"
+)
+
+// Strategy defines how many of the available locales are returned by NegotiateLanguages
+type Strategy int
+
+const (
+	// Filtering returns all available locales matching any of the requested ones in the order of the requested locales.
+	// This is the default strategy of @fluent/langneg.
+	Filtering Strategy = iota
+
+	// Matching returns the best matching available locale for every requested locale
+	Matching
+
+	// Lookup returns a single locale: the best match for the most preferred requested locale or the default locale
+	Lookup
+)
+
+// NegotiateLanguages negotiates the locales to use out of the available ones based on the requested ones.
+// The requested locales are expected to be ordered by preference. The result is ordered by preference as well
+// and can be passed to fluent.NewBundle (the first locale being the primary one) or used to order bundles.
+//
+// If defaultLocale is not language.Und, it is appended to the result if it is not already part of it.
+// Using the Lookup strategy, the result consists of the default locale if no available locale matches.
+func NegotiateLanguages(requested, available []language.Tag, strategy Strategy, defaultLocale language.Tag) []language.Tag {
+	supported := filterMatches(requested, available, strategy)
+
+	if strategy == Lookup {
+		if len(supported) == 0 && defaultLocale != language.Und {
+			supported = append(supported, defaultLocale)
+		}
+		return supported
+	}
+
+	if defaultLocale != language.Und && !contains(supported, defaultLocale) {
+		supported = append(supported, defaultLocale)
+	}
+	return supported
+}
+
+// ParseAcceptLanguage parses the value of an Accept-Language header into locales ordered by their quality.
+// Invalid entries are skipped.
+func ParseAcceptLanguage(header string) []language.Tag {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err == nil {
+		return tags
+	}
+
+	// Parse the entries one by one to skip the invalid ones
+	type entry struct {
+		tag     language.Tag
+		quality float32
+	}
+	var entries []entry
+	for _, raw := range strings.Split(header, ",") {
+		parsed, qualities, err := language.ParseAcceptLanguage(raw)
+		if err != nil {
+			continue
+		}
+		for i, tag := range parsed {
+			entries = append(entries, entry{tag: tag, quality: qualities[i]})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].quality > entries[j].quality
+	})
+
+	result := make([]language.Tag, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, entry.tag)
+	}
+	return result
+}
+
+// locale holds the subtags relevant for language negotiation; undefined subtags are empty
+type locale struct {
+	language string
+	script   string
+	region   string
+	variant  string
+}
+
+// newLocale extracts the subtags of a tag without inferring any missing ones
+func newLocale(tag language.Tag) locale {
+	base, script, region := tag.Raw()
+	result := locale{}
+	if base != (language.Base{}) && base.String() != "und" {
+		result.language = strings.ToLower(base.String())
+	}
+	if script != (language.Script{}) {
+		result.script = strings.ToLower(script.String())
+	}
+	if region != (language.Region{}) {
+		result.region = strings.ToLower(region.String())
+	}
+	variants := make([]string, 0)
+	for _, variant := range tag.Variants() {
+		variants = append(variants, strings.ToLower(variant.String()))
+	}
+	result.variant = strings.Join(variants, "-")
+	return result
+}
+
+// matches checks whether the locale matches the other one.
+// If thisRange or otherRange is true, undefined subtags of the respective locale match any subtag.
+func (loc locale) matches(other locale, thisRange, otherRange bool) bool {
+	parts := [][2]string{
+		{loc.language, other.language},
+		{loc.script, other.script},
+		{loc.region, other.region},
+		{loc.variant, other.variant},
+	}
+	for _, part := range parts {
+		if thisRange && part[0] == "" {
+			continue
+		}
+		if otherRange && part[1] == "" {
+			continue
+		}
+		if part[0] != part[1] {
+			return false
+		}
+	}
+	return true
+}
+
+// addLikelySubtags replaces the script and region of the locale with the most likely ones.
+// It returns false if they can not be inferred.
+func (loc *locale) addLikelySubtags() bool {
+	raw := loc.language
+	if loc.script != "" {
+		raw += "-" + loc.script
+	}
+	if loc.region != "" {
+		raw += "-" + loc.region
+	}
+	tag, err := language.Parse(raw)
+	if err != nil {
+		return false
+	}
+
+	script, scriptConfidence := tag.Script()
+	region, regionConfidence := tag.Region()
+	if scriptConfidence == language.No || regionConfidence == language.No {
+		return false
+	}
+	loc.script = strings.ToLower(script.String())
+	loc.region = strings.ToLower(region.String())
+	return true
+}
+
+// filterMatches implements the negotiation algorithm of @fluent/langneg.
+// For each requested locale, the available locales are compared in multiple steps with decreasing precision.
+func filterMatches(requested, available []language.Tag, strategy Strategy) []language.Tag {
+	var supported []language.Tag
+	remaining := make([]language.Tag, len(available))
+	copy(remaining, available)
+
+	// match adds the remaining locales that satisfy the given condition to the supported ones.
+	// It returns true if the negotiation of the current requested locale is finished.
+	match := func(condition func(tag language.Tag) bool) bool {
+		for i := 0; i < len(remaining); i++ {
+			tag := remaining[i]
+			if !condition(tag) {
+				continue
+			}
+			supported = append(supported, tag)
+			remaining = append(remaining[:i], remaining[i+1:]...)
+			if strategy != Filtering {
+				return true
+			}
+			i--
+		}
+		return false
+	}
+
+	for _, requestedTag := range requested {
+		requestedLocale := newLocale(requestedTag)
+		if requestedLocale.language == "" {
+			continue
+		}
+
+		done := func() bool {
+			// 1) Attempt to make an exact match
+			if match(func(tag language.Tag) bool {
+				return strings.EqualFold(tag.String(), requestedTag.String())
+			}) {
+				return true
+			}
+
+			// 2) Attempt to match against the available range, e.g. 'en' matches 'en-US'
+			if match(func(tag language.Tag) bool {
+				return newLocale(tag).matches(requestedLocale, true, false)
+			}) {
+				return true
+			}
+
+			// 3) Attempt to match against the maximized version of the requested locale, e.g. 'en' becomes 'en-Latn-US'
+			if requestedLocale.addLikelySubtags() {
+				if match(func(tag language.Tag) bool {
+					return newLocale(tag).matches(requestedLocale, true, false)
+				}) {
+					return true
+				}
+			}
+
+			// 4) Attempt to match against a different variant of the same locale
+			requestedLocale.variant = ""
+			if match(func(tag language.Tag) bool {
+				return newLocale(tag).matches(requestedLocale, true, true)
+			}) {
+				return true
+			}
+
+			// 5) Attempt to match against the likely subtags of the requested locale without its region
+			requestedLocale.region = ""
+			if requestedLocale.addLikelySubtags() {
+				if match(func(tag language.Tag) bool {
+					return newLocale(tag).matches(requestedLocale, true, false)
+				}) {
+					return true
+				}
+			}
+
+			// 6) Attempt to match against a different region of the same locale
+			requestedLocale.region = ""
+			return match(func(tag language.Tag) bool {
+				return newLocale(tag).matches(requestedLocale, true, true)
+			})
+		}()
+
+		if done && strategy == Lookup {
+			return supported
+		}
+	}
+
+	return supported
+}
+
+// contains checks whether the tags contain the given one
+func contains(tags []language.Tag, tag language.Tag) bool {
+	for _, contained := range tags {
+		if contained == tag {
+			return true
+		}
+	}
+	return false
+}
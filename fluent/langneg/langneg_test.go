@@ -0,0 +1,85 @@
+package langneg
+
+import (
+	"golang.org/x/text/language"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tags parses a comma separated list of locales
+func tags(list string) []language.Tag {
+	result := []language.Tag{}
+	if list == "" {
+		return result
+	}
+	for _, raw := range strings.Split(list, ",") {
+		result = append(result, language.MustParse(raw))
+	}
+	return result
+}
+
+func TestNegotiateLanguages(t *testing.T) {
+	tests := []struct {
+		strategy      Strategy
+		requested     string
+		available     string
+		defaultLocale string
+		expected      string
+	}{
+		// Exact matches
+		{Filtering, "en-US", "en-US,fr", "", "en-US"},
+		{Filtering, "en-US,fr", "de,fr,en-US", "", "en-US,fr"},
+
+		// Available ranges
+		{Filtering, "en-US", "en,fr", "", "en"},
+		{Filtering, "de-DE,en", "en-US,de,fr", "", "de,en-US"},
+
+		// Likely subtags
+		{Filtering, "en", "en-GB,en-US", "", "en-US,en-GB"},
+		{Filtering, "zh-TW", "zh-Hant,zh-Hans", "", "zh-Hant"},
+
+		// Other regions of the same language
+		{Filtering, "fr-CA", "fr-FR,fr", "", "fr,fr-FR"},
+
+		// The matching strategy only returns the best match per requested locale
+		{Matching, "en,fr", "en-GB,en-US,fr", "", "en-US,fr"},
+		{Filtering, "en,fr", "en-GB,en-US,fr", "", "en-US,en-GB,fr"},
+
+		// The lookup strategy returns a single locale
+		{Lookup, "de-AT,fr", "en,fr,de", "en", "de"},
+		{Lookup, "it", "en,fr", "en", "en"},
+
+		// The default locale is appended
+		{Filtering, "fr", "fr,en", "en", "fr,en"},
+		{Filtering, "it", "fr,en", "en", "en"},
+		{Filtering, "it", "fr,en", "", ""},
+	}
+
+	for _, test := range tests {
+		defaultLocale := language.Und
+		if test.defaultLocale != "" {
+			defaultLocale = language.MustParse(test.defaultLocale)
+		}
+		result := NegotiateLanguages(tags(test.requested), tags(test.available), test.strategy, defaultLocale)
+		if result == nil {
+			result = []language.Tag{}
+		}
+		if !reflect.DeepEqual(result, tags(test.expected)) {
+			t.Fatalf("negotiating '%s' out of '%s' (strategy %d) resulted in %v, expected '%s'",
+				test.requested, test.available, test.strategy, result, test.expected)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	result := ParseAcceptLanguage("en;q=0.8, fr-CH, fr;q=0.9")
+	if !reflect.DeepEqual(result, tags("fr-CH,fr,en")) {
+		t.Fatalf("unexpected result %v", result)
+	}
+
+	result = ParseAcceptLanguage("en;q=0.5, invalid-locale-!, de")
+	if !reflect.DeepEqual(result, tags("de,en")) {
+		t.Fatalf("unexpected result %v", result)
+	}
+}
@@ -0,0 +1,111 @@
+package fluent
+
+import (
+	"errors"
+	"fmt"
+	"golang.org/x/text/language"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LocalePlaceholder is the placeholder of the locale in the path templates passed to LoadBundlesFS
+const LocalePlaceholder = "{locale}"
+
+// LoadBundlesFS discovers the locales and resources of a file system using a path template and builds a bundle per locale.
+// The template is a pattern in the form of fs.Glob in which a single path element contains the LocalePlaceholder,
+// e.g. 'locales/{locale}/*.ftl' or 'translations/messages.{locale}.ftl'. The path element containing the placeholder
+// may not contain any other wildcards.
+//
+// All files are parsed concurrently. Their resources are added to the bundle of their locale in lexical order of their paths.
+// The returned errors are the parser errors, the errors raised while adding the resources to the bundles and the errors
+// of paths whose locale could not be parsed; all of them point to the file they were raised for and are ordered by it.
+// An error is returned if the template is malformed or if a file can not be read.
+func LoadBundlesFS(fsys fs.FS, template string) (map[language.Tag]*Bundle, []error, error) {
+	elements := strings.Split(template, "/")
+	localeIndex := -1
+	for i, element := range elements {
+		if strings.Contains(element, LocalePlaceholder) {
+			if localeIndex >= 0 || strings.Count(element, LocalePlaceholder) > 1 {
+				return nil, nil, fmt.Errorf("the template '%s' may only contain a single %s placeholder", template, LocalePlaceholder)
+			}
+			localeIndex = i
+		}
+	}
+	if localeIndex < 0 {
+		return nil, nil, fmt.Errorf("the template '%s' does not contain a %s placeholder", template, LocalePlaceholder)
+	}
+	localeElement := elements[localeIndex]
+	localePrefix := localeElement[:strings.Index(localeElement, LocalePlaceholder)]
+	localeSuffix := localeElement[strings.Index(localeElement, LocalePlaceholder)+len(LocalePlaceholder):]
+
+	paths, err := fs.Glob(fsys, strings.Replace(template, LocalePlaceholder, "*", 1))
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+
+	// Read and parse all files concurrently; paths whose locale can not be parsed only produce an error
+	type result struct {
+		locale   language.Tag
+		resource *Resource
+		errs     []error
+		err      error
+	}
+	results := make([]result, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		element := strings.Split(path, "/")[localeIndex]
+		raw := strings.TrimSuffix(strings.TrimPrefix(element, localePrefix), localeSuffix)
+		locale, err := language.Parse(raw)
+		if err != nil {
+			localeErr := fmt.Errorf("%s: invalid locale '%s': %w", path, raw, err)
+			results[i] = result{locale: language.Und, resource: nil, errs: []error{localeErr}, err: nil}
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, path string, locale language.Tag) {
+			defer wg.Done()
+			source, err := fs.ReadFile(fsys, path)
+			if err != nil {
+				results[i] = result{locale: locale, resource: nil, errs: nil, err: err}
+				return
+			}
+			resource, parserErrs := newResource(path, string(source))
+			resourceErrs := make([]error, 0, len(parserErrs))
+			for _, parserErr := range parserErrs {
+				resourceErrs = append(resourceErrs, parserErr)
+			}
+			results[i] = result{locale: locale, resource: resource, errs: resourceErrs, err: nil}
+		}(i, path, locale)
+	}
+	wg.Wait()
+
+	// Assemble the bundles and collect the errors in lexical order of the paths
+	var errs []error
+	bundles := make(map[language.Tag]*Bundle)
+	for i, path := range paths {
+		if results[i].err != nil {
+			var pathErr *fs.PathError
+			if errors.As(results[i].err, &pathErr) {
+				return nil, nil, results[i].err
+			}
+			return nil, nil, fmt.Errorf("%s: %w", path, results[i].err)
+		}
+		errs = append(errs, results[i].errs...)
+		if results[i].resource == nil {
+			continue
+		}
+
+		bundle := bundles[results[i].locale]
+		if bundle == nil {
+			bundle = NewBundle(results[i].locale)
+			bundles[results[i].locale] = bundle
+		}
+		errs = append(errs, bundle.AddResource(results[i].resource)...)
+	}
+
+	return bundles, errs, nil
+}
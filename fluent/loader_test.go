@@ -0,0 +1,73 @@
+package fluent
+
+import (
+	"golang.org/x/text/language"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadBundlesFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/en-US/main.ftl":        {Data: []byte("hello = Hello\n")},
+		"locales/en-US/errors.ftl":      {Data: []byte("not-found = Not found\nhello = Duplicate\n")},
+		"locales/de/main.ftl":           {Data: []byte("hello = Hallo\nbroken = Value }\n")},
+		"locales/not a locale/main.ftl": {Data: []byte("hello = ?\n")},
+		"locales/README.md":             {Data: []byte("Translations")},
+	}
+
+	bundles, errs, err := LoadBundlesFS(fsys, "locales/{locale}/*.ftl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bundles) != 2 || bundles[language.AmericanEnglish] == nil || bundles[language.German] == nil {
+		t.Fatalf("unexpected bundles %v", bundles)
+	}
+
+	tests := []struct {
+		locale   language.Tag
+		key      string
+		expected string
+	}{
+		{language.AmericanEnglish, "hello", "Duplicate"},
+		{language.AmericanEnglish, "not-found", "Not found"},
+		{language.German, "hello", "Hallo"},
+	}
+	for _, test := range tests {
+		result, _, err := bundles[test.locale].FormatMessage(test.key)
+		if err != nil || result != test.expected {
+			t.Fatalf("message '%s' of locale '%s' resolved to '%s', %v", test.key, test.locale, result, err)
+		}
+	}
+
+	// The errors are attributed to their files in lexical order of the paths
+	expected := []string{
+		"locales/de/main.ftl:2:16: ",
+		"locales/en-US/main.ftl: message 'hello' is already defined",
+		"locales/not a locale/main.ftl: invalid locale",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(errs[i].Error(), prefix) {
+			t.Fatalf("error '%s' does not start with '%s'", errs[i], prefix)
+		}
+	}
+
+	// Placeholders may be part of a path element
+	fsys = fstest.MapFS{
+		"messages.en.ftl": {Data: []byte("hello = Hello\n")},
+		"messages.fr.ftl": {Data: []byte("hello = Bonjour\n")},
+	}
+	bundles, errs, err = LoadBundlesFS(fsys, "messages.{locale}.ftl")
+	if err != nil || len(errs) > 0 || len(bundles) != 2 || bundles[language.French] == nil {
+		t.Fatalf("unexpected result %v, %v, %v", bundles, errs, err)
+	}
+
+	for _, template := range []string{"locales/*.ftl", "{locale}/{locale}.ftl", "[" + LocalePlaceholder} {
+		if _, _, err := LoadBundlesFS(fsys, template); err == nil {
+			t.Fatalf("expected an error for the template '%s'", template)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package fluent
+
+import (
+	"errors"
+	"golang.org/x/text/language"
+)
+
+// Localization holds an ordered chain of bundles, e.g. for the locales 'de-AT', 'de' and 'en'.
+// Messages are formatted using the first bundle that contains them, so incomplete translations fall back to the
+// bundles of the following locales. This mirrors the Localization classes of fluent.js like DOMLocalization.
+// A Localization is safe for concurrent use as long as its bundles are.
+type Localization struct {
+	bundles []*Bundle
+}
+
+// NewLocalization creates a new localization from the given bundles in the order of preference
+func NewLocalization(bundles ...*Bundle) *Localization {
+	copied := make([]*Bundle, len(bundles))
+	copy(copied, bundles)
+	return &Localization{
+		bundles: copied,
+	}
+}
+
+// Bundles returns the bundles of the localization in the order of preference
+func (localization *Localization) Bundles() []*Bundle {
+	bundles := make([]*Bundle, len(localization.bundles))
+	copy(bundles, localization.bundles)
+	return bundles
+}
+
+// FormatMessage formats the message with the given key using the first bundle that contains it.
+// Besides the results of Bundle.FormatMessage, it returns the primary locale of the bundle that satisfied the message.
+// If no bundle contains the message, an UnknownMessageError and language.Und are returned.
+func (localization *Localization) FormatMessage(key string, contexts ...*FormatContext) (string, language.Tag, []error, error) {
+	for _, bundle := range localization.bundles {
+		result, errs, err := bundle.FormatMessage(key, contexts...)
+		if isMissing(err) {
+			continue
+		}
+		return result, bundle.locales[0], errs, err
+	}
+	return "", language.Und, nil, &UnknownMessageError{ErrorContext: ErrorContext{}, ID: key, Attribute: ""}
+}
+
+// FormatAttribute formats the attribute with the given name of the message with the given key using the first bundle
+// that contains both. Besides the results of Bundle.FormatAttribute, it returns the primary locale of the bundle that
+// satisfied the attribute. If no bundle contains the attribute, an UnknownMessageError and language.Und are returned.
+func (localization *Localization) FormatAttribute(key, attribute string, contexts ...*FormatContext) (string, language.Tag, []error, error) {
+	for _, bundle := range localization.bundles {
+		result, errs, err := bundle.FormatAttribute(key, attribute, contexts...)
+		if isMissing(err) {
+			continue
+		}
+		return result, bundle.locales[0], errs, err
+	}
+	return "", language.Und, nil, &UnknownMessageError{ErrorContext: ErrorContext{}, ID: key, Attribute: attribute}
+}
+
+// FormatMessageWithAttributes formats the value and all attributes of the message with the given key using the first
+// bundle that contains it. Besides the results of Bundle.FormatMessageWithAttributes, it returns the primary locale
+// of the bundle that satisfied the message. If no bundle contains the message, an UnknownMessageError and language.Und are returned.
+func (localization *Localization) FormatMessageWithAttributes(key string, contexts ...*FormatContext) (string, map[string]string, language.Tag, []error, error) {
+	for _, bundle := range localization.bundles {
+		value, attributes, errs, err := bundle.FormatMessageWithAttributes(key, contexts...)
+		if isMissing(err) {
+			continue
+		}
+		return value, attributes, bundle.locales[0], errs, err
+	}
+	return "", nil, language.Und, nil, &UnknownMessageError{ErrorContext: ErrorContext{}, ID: key, Attribute: ""}
+}
+
+// HasMessage checks whether any bundle of the localization contains a message with the given key
+func (localization *Localization) HasMessage(key string) bool {
+	for _, bundle := range localization.bundles {
+		if bundle.HasMessage(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// isMissing checks whether the error returned by a bundle indicates that the requested message or attribute does not exist
+func isMissing(err error) bool {
+	var unknownMessage *UnknownMessageError
+	return errors.As(err, &unknownMessage)
+}
@@ -0,0 +1,58 @@
+package fluent
+
+import (
+	"errors"
+	"golang.org/x/text/language"
+	"testing"
+)
+
+func TestLocalization(t *testing.T) {
+	austrian := newTestBundleWithLocale(t, language.MustParse("de-AT"), `
+january = Jänner
+`)
+	german := newTestBundleWithLocale(t, language.German, `
+january = Januar
+hello = Hallo, { $name }!
+login = Anmelden
+`)
+	english := newTestBundleWithLocale(t, language.English, `
+hello = Hello, { $name }!
+login = Log in
+    .title = Log in to your account
+goodbye = Goodbye
+`)
+	localization := NewLocalization(austrian, german, english)
+
+	tests := []struct {
+		key      string
+		expected string
+		locale   language.Tag
+	}{
+		{"january", "Jänner", language.MustParse("de-AT")},
+		{"hello", "Hallo, Jane!", language.German},
+		{"goodbye", "Goodbye", language.English},
+	}
+	for _, test := range tests {
+		result, locale, errs, err := localization.FormatMessage(test.key, WithVariable("name", "Jane"))
+		if err != nil || len(errs) > 0 {
+			t.Fatalf("message '%s' could not be formatted: %v, %v", test.key, err, errs)
+		}
+		if result != test.expected || locale != test.locale {
+			t.Fatalf("message '%s' resolved to '%s' (%s), expected '%s' (%s)", test.key, result, locale, test.expected, test.locale)
+		}
+	}
+
+	// Missing attributes fall back as well
+	result, locale, _, err := localization.FormatAttribute("login", "title")
+	if err != nil || result != "Log in to your account" || locale != language.English {
+		t.Fatalf("unexpected attribute '%s' (%s), %v", result, locale, err)
+	}
+
+	var unknownMessage *UnknownMessageError
+	if _, locale, _, err := localization.FormatMessage("missing"); !errors.As(err, &unknownMessage) || locale != language.Und {
+		t.Fatalf("expected an UnknownMessageError, got '%v' (%s)", err, locale)
+	}
+	if !localization.HasMessage("goodbye") || localization.HasMessage("missing") {
+		t.Fatal("unexpected result of HasMessage")
+	}
+}
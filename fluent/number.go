@@ -0,0 +1,378 @@
+package fluent
+
+import (
+	"golang.org/x/text/language"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The number styles supported by NumberFormatOptions.Style.
+// Currencies are not supported as the CLDR currency patterns and names are not available through golang.org/x/text.
+const (
+	NumberStyleDecimal = "decimal"
+	NumberStylePercent = "percent"
+)
+
+// The plural rule types supported by NumberFormatOptions.Type
+const (
+	NumberTypeCardinal = "cardinal"
+	NumberTypeOrdinal  = "ordinal"
+)
+
+// NumberFormatOptions holds the subset of the options of JavaScript's Intl.NumberFormat that are used to format a NumberValue.
+// A value of 0 for MinimumIntegerDigits, MinimumSignificantDigits and MaximumSignificantDigits means that the option is not set.
+// A value of -1 for MinimumFractionDigits and MaximumFractionDigits means that the default of the style is used.
+// Type does not affect the formatting but selects whether cardinal or ordinal plural rules are used in select expressions.
+type NumberFormatOptions struct {
+	Type                     string
+	Style                    string
+	UseGrouping              bool
+	MinimumIntegerDigits     int
+	MinimumFractionDigits    int
+	MaximumFractionDigits    int
+	MinimumSignificantDigits int
+	MaximumSignificantDigits int
+}
+
+// DefaultNumberFormatOptions returns the options Intl.NumberFormat uses if no explicit options are passed
+func DefaultNumberFormatOptions() *NumberFormatOptions {
+	return &NumberFormatOptions{
+		Type:                     NumberTypeCardinal,
+		Style:                    NumberStyleDecimal,
+		UseGrouping:              true,
+		MinimumIntegerDigits:     0,
+		MinimumFractionDigits:    -1,
+		MaximumFractionDigits:    -1,
+		MinimumSignificantDigits: 0,
+		MaximumSignificantDigits: 0,
+	}
+}
+
+// fractionDigits returns the effective minimum and maximum fraction digits respecting the defaults of the style
+func (options *NumberFormatOptions) fractionDigits() (int, int) {
+	minDefault, maxDefault := 0, 3
+	if options.Style == NumberStylePercent {
+		maxDefault = 0
+	}
+
+	min := options.MinimumFractionDigits
+	if min < 0 {
+		min = minDefault
+	}
+	max := options.MaximumFractionDigits
+	if max < 0 {
+		max = maxDefault
+	}
+	if max < min {
+		max = min
+	}
+	return min, max
+}
+
+// decimal is an exact base 10 representation of a number used for formatting and plural selection
+type decimal struct {
+	negative  bool
+	integer   string // integer digits without leading zeros; empty if the integer part is zero
+	fraction  string // fraction digits including trailing zeros
+	nonFinite string // "NaN" or "Infinity" if the number is not finite; the digits are empty then
+}
+
+// maxDecimalExponent limits the exponents parseDecimal accepts, as the digits of a decimal are stored in full
+const maxDecimalExponent = 1000
+
+// parseDecimal parses a decimal string like '-12.340' or '1.234e-5'
+func parseDecimal(raw string) (decimal, bool) {
+	result := decimal{}
+	if strings.HasPrefix(raw, "-") {
+		result.negative = true
+		raw = raw[1:]
+	}
+
+	exponent := 0
+	if index := strings.IndexAny(raw, "eE"); index >= 0 {
+		parsed, err := strconv.Atoi(raw[index+1:])
+		if err != nil || parsed > maxDecimalExponent || parsed < -maxDecimalExponent {
+			return decimal{}, false
+		}
+		exponent = parsed
+		raw = raw[:index]
+	}
+
+	integer, fraction := raw, ""
+	if dot := strings.IndexByte(raw, '.'); dot >= 0 {
+		integer, fraction = raw[:dot], raw[dot+1:]
+	}
+	if integer == "" && fraction == "" {
+		return decimal{}, false
+	}
+	if !isDigits(integer) || !isDigits(fraction) {
+		return decimal{}, false
+	}
+
+	result.integer = strings.TrimLeft(integer, "0")
+	result.fraction = fraction
+	return result.shift(exponent), true
+}
+
+// decimalFromFloat converts a float into its shortest exact decimal representation.
+// NaN and infinite floats are kept as non-finite decimals, which are formatted like JavaScript does.
+func decimalFromFloat(value float64, bitSize int) decimal {
+	switch {
+	case math.IsNaN(value):
+		return decimal{negative: false, integer: "", fraction: "", nonFinite: "NaN"}
+	case math.IsInf(value, 0):
+		return decimal{negative: value < 0, integer: "", fraction: "", nonFinite: "Infinity"}
+	}
+	parsed, _ := parseDecimal(strconv.FormatFloat(value, 'f', -1, bitSize))
+	return parsed
+}
+
+// isZero checks whether all digits of the decimal are zero
+func (d decimal) isZero() bool {
+	return d.nonFinite == "" && strings.Trim(d.integer+d.fraction, "0") == ""
+}
+
+// shift multiplies the decimal by 10^n; n may be negative
+func (d decimal) shift(n int) decimal {
+	if d.nonFinite != "" {
+		return d
+	}
+	switch {
+	case n > 0 && n <= len(d.fraction):
+		d.integer, d.fraction = d.integer+d.fraction[:n], d.fraction[n:]
+	case n > 0:
+		d.integer, d.fraction = d.integer+d.fraction+strings.Repeat("0", n-len(d.fraction)), ""
+	case n < 0 && -n <= len(d.integer):
+		split := len(d.integer) + n
+		d.integer, d.fraction = d.integer[:split], d.integer[split:]+d.fraction
+	case n < 0:
+		d.integer, d.fraction = "", strings.Repeat("0", -n-len(d.integer))+d.integer+d.fraction
+	}
+	d.integer = strings.TrimLeft(d.integer, "0")
+	return d
+}
+
+// equals checks whether two decimals represent the same number, ignoring trailing zeros and the sign of zero
+func (d decimal) equals(other decimal) bool {
+	if d.nonFinite != "" || other.nonFinite != "" {
+		// NaN never equals anything, infinities only equal infinities of the same sign
+		return d.nonFinite == "Infinity" && other.nonFinite == "Infinity" && d.negative == other.negative
+	}
+	if d.isZero() && other.isZero() {
+		return true
+	}
+	return d.negative == other.negative && d.integer == other.integer &&
+		strings.TrimRight(d.fraction, "0") == strings.TrimRight(other.fraction, "0")
+}
+
+// String returns the plain representation of the decimal
+func (d decimal) String() string {
+	if d.nonFinite == "NaN" {
+		return "NaN"
+	}
+	if d.nonFinite != "" {
+		if d.negative {
+			return "-" + d.nonFinite
+		}
+		return d.nonFinite
+	}
+	result := d.integer
+	if result == "" {
+		result = "0"
+	}
+	if d.fraction != "" {
+		result += "." + d.fraction
+	}
+	if d.negative {
+		result = "-" + result
+	}
+	return result
+}
+
+// roundDigits rounds the concatenated digits of the decimal to the given length, rounding half away from zero like JavaScript does
+func (d decimal) roundDigits(keep int) decimal {
+	digits := d.integer + d.fraction
+	if keep >= len(digits) {
+		return d
+	}
+	if keep < 0 {
+		keep = 0
+	}
+
+	kept := digits[:keep]
+	intLen := len(d.integer)
+	if digits[keep] >= '5' {
+		kept = incrementDigits(kept)
+		if len(kept) > keep {
+			intLen++
+		}
+	}
+	for len(kept) < intLen {
+		kept += "0"
+	}
+
+	d.integer = strings.TrimLeft(kept[:intLen], "0")
+	d.fraction = kept[intLen:]
+	return d
+}
+
+// roundFraction rounds the decimal to a maximum amount of fraction digits
+func (d decimal) roundFraction(max int) decimal {
+	if len(d.fraction) <= max {
+		return d
+	}
+	return d.roundDigits(len(d.integer) + max)
+}
+
+// roundSignificant rounds the decimal to a maximum amount of significant digits
+func (d decimal) roundSignificant(max int) decimal {
+	digits := d.integer + d.fraction
+	leadingZeros := len(digits) - len(strings.TrimLeft(digits, "0"))
+	return d.roundDigits(leadingZeros + max)
+}
+
+// significantDigits returns the amount of significant digits of the decimal (including trailing zeros)
+func (d decimal) significantDigits() int {
+	digits := d.integer + d.fraction
+	return len(strings.TrimLeft(digits, "0"))
+}
+
+// applyOptions rounds and pads the decimal according to the given options
+func (d decimal) applyOptions(options *NumberFormatOptions) decimal {
+	if d.nonFinite != "" {
+		return d
+	}
+	if options.MinimumSignificantDigits > 0 || options.MaximumSignificantDigits > 0 {
+		minSignificant, maxSignificant := options.MinimumSignificantDigits, options.MaximumSignificantDigits
+		if minSignificant <= 0 {
+			minSignificant = 1
+		}
+		if maxSignificant <= 0 {
+			maxSignificant = 21
+		}
+		if maxSignificant < minSignificant {
+			maxSignificant = minSignificant
+		}
+
+		d = d.roundSignificant(maxSignificant)
+		d.fraction = strings.TrimRight(d.fraction, "0")
+		significant := d.significantDigits()
+		if d.isZero() {
+			significant = 1
+		}
+		for ; significant < minSignificant; significant++ {
+			d.fraction += "0"
+		}
+	} else {
+		minFraction, maxFraction := options.fractionDigits()
+		d = d.roundFraction(maxFraction)
+		d.fraction = strings.TrimRight(d.fraction, "0")
+		for len(d.fraction) < minFraction {
+			d.fraction += "0"
+		}
+	}
+
+	return d
+}
+
+// pluralOperands returns the digits of the decimal in the form expected by plural.Rules.MatchDigits
+func (d decimal) pluralOperands() ([]byte, int, int) {
+	integer := d.integer
+	if integer == "" {
+		integer = "0"
+	}
+	digits := make([]byte, 0, len(integer)+len(d.fraction))
+	for _, digit := range integer + d.fraction {
+		digits = append(digits, byte(digit-'0'))
+	}
+	return digits, len(integer), len(d.fraction)
+}
+
+// formatNumber formats a number according to the given options and the conventions of the given locale
+func formatNumber(value decimal, options *NumberFormatOptions, locale language.Tag) string {
+	symbols := lookupNumberSymbols(locale)
+
+	if value.nonFinite == "NaN" {
+		return symbols.nan
+	}
+	if value.nonFinite != "" {
+		result := symbols.infinity
+		if options.Style == NumberStylePercent {
+			result = strings.Replace(symbols.percentPattern, "{0}", result, 1)
+		}
+		if value.negative {
+			result = strings.Replace(symbols.minusPattern, "{0}", result, 1)
+		}
+		return result
+	}
+
+	if options.Style == NumberStylePercent {
+		value = value.shift(2)
+	}
+	value = value.applyOptions(options)
+
+	integer := value.integer
+	for len(integer) < options.MinimumIntegerDigits || integer == "" {
+		integer = "0" + integer
+	}
+	if options.UseGrouping {
+		integer = symbols.groupInteger(integer)
+	}
+
+	result := symbols.localizeDigits(integer)
+	if value.fraction != "" {
+		result += symbols.decimal + symbols.localizeDigits(value.fraction)
+	}
+
+	if options.Style == NumberStylePercent {
+		result = strings.Replace(symbols.percentPattern, "{0}", result, 1)
+	}
+
+	if value.negative {
+		result = strings.Replace(symbols.minusPattern, "{0}", result, 1)
+	}
+	return result
+}
+
+// groupDigits inserts the separator between every group of the given size, counted from the right
+func groupDigits(digits string, separator string, size int) string {
+	if len(digits) <= size {
+		return digits
+	}
+	var builder strings.Builder
+	first := len(digits) % size
+	if first > 0 {
+		builder.WriteString(digits[:first])
+	}
+	for i := first; i < len(digits); i += size {
+		if i > 0 {
+			builder.WriteString(separator)
+		}
+		builder.WriteString(digits[i : i+size])
+	}
+	return builder.String()
+}
+
+// incrementDigits adds one to a string of decimal digits
+func incrementDigits(digits string) string {
+	bytes := []byte(digits)
+	for i := len(bytes) - 1; i >= 0; i-- {
+		if bytes[i] < '9' {
+			bytes[i]++
+			return string(bytes)
+		}
+		bytes[i] = '0'
+	}
+	return "1" + string(bytes)
+}
+
+// isDigits checks whether the string only consists of ASCII digits
+func isDigits(str string) bool {
+	for _, char := range str {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+	return true
+}
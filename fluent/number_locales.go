@@ -0,0 +1,116 @@
+package fluent
+
+import (
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+	"math"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// numberSymbols holds the CLDR data required to format numbers for a single locale
+type numberSymbols struct {
+	digits         [10]rune
+	decimal        string
+	group          string
+	primaryGroup   int
+	secondaryGroup int
+	percentPattern string // The pattern of a percentage; '{0}' is replaced with the formatted number
+	minusPattern   string // The pattern of a negative number; '{0}' is replaced with the formatted number
+	nan            string
+	infinity       string
+}
+
+// numberSymbolsCache caches the symbols of every locale they have already been looked up for
+var numberSymbolsCache sync.Map
+
+// lookupNumberSymbols returns the number symbols of the given locale.
+// As golang.org/x/text does not expose its CLDR number data directly, the symbols are extracted by formatting
+// a few sample numbers using its message printer.
+func lookupNumberSymbols(locale language.Tag) *numberSymbols {
+	if cached, ok := numberSymbolsCache.Load(locale); ok {
+		return cached.(*numberSymbols)
+	}
+
+	printer := message.NewPrinter(locale)
+	symbols := &numberSymbols{
+		decimal:        ".",
+		group:          ",",
+		primaryGroup:   3,
+		secondaryGroup: 3,
+		percentPattern: "{0}%",
+		minusPattern:   "-{0}",
+		nan:            printer.Sprint(number.Decimal(math.NaN())),
+		infinity:       printer.Sprint(number.Decimal(math.Inf(1))),
+	}
+	for i := range symbols.digits {
+		symbols.digits[i] = rune('0' + i)
+	}
+
+	// The sample contains every digit once, a decimal separator and multiple grouping separators
+	sample := []rune(printer.Sprint(number.Decimal(1234567890.5, number.MinFractionDigits(1))))
+	var digits []rune
+	var separators []string
+	var groupLengths []int
+	separator := ""
+	for _, char := range sample {
+		if unicode.IsDigit(char) {
+			if separator != "" || len(digits) == 0 {
+				separators = append(separators, separator)
+				groupLengths = append(groupLengths, 0)
+				separator = ""
+			}
+			digits = append(digits, char)
+			groupLengths[len(groupLengths)-1]++
+			continue
+		}
+		separator += string(char)
+	}
+	if len(digits) == 11 && len(separators) >= 3 {
+		for i := 0; i < 9; i++ {
+			symbols.digits[i+1] = digits[i]
+		}
+		symbols.digits[0] = digits[9]
+		symbols.decimal = separators[len(separators)-1]
+		symbols.group = separators[len(separators)-2]
+		symbols.primaryGroup = groupLengths[len(groupLengths)-2]
+		symbols.secondaryGroup = groupLengths[len(groupLengths)-3]
+	}
+
+	localize := func(digits string) string {
+		return strings.Map(func(char rune) rune {
+			return symbols.digits[char-'0']
+		}, digits)
+	}
+	if percent := printer.Sprint(number.Percent(0.5)); strings.Contains(percent, localize("50")) {
+		symbols.percentPattern = strings.Replace(percent, localize("50"), "{0}", 1)
+	}
+	if minus := printer.Sprint(number.Decimal(-1)); strings.Contains(minus, localize("1")) {
+		symbols.minusPattern = strings.Replace(minus, localize("1"), "{0}", 1)
+	}
+
+	numberSymbolsCache.Store(locale, symbols)
+	return symbols
+}
+
+// groupInteger inserts the grouping separators of the locale into a string of integer digits
+func (symbols *numberSymbols) groupInteger(digits string) string {
+	if len(digits) <= symbols.primaryGroup || symbols.primaryGroup <= 0 {
+		return digits
+	}
+	head := digits[:len(digits)-symbols.primaryGroup]
+	tail := digits[len(digits)-symbols.primaryGroup:]
+	return groupDigits(head, symbols.group, symbols.secondaryGroup) + symbols.group + tail
+}
+
+// localizeDigits replaces the ASCII digits of the given string with the ones of the locale
+func (symbols *numberSymbols) localizeDigits(str string) string {
+	return strings.Map(func(char rune) rune {
+		if char >= '0' && char <= '9' {
+			return symbols.digits[char-'0']
+		}
+		return char
+	}, str)
+}
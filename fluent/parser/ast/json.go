@@ -0,0 +1,68 @@
+package ast
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// MarshalJSON marshals an AST into JSON.
+// If withSpans is false, this is equivalent to json.Marshal. Otherwise every node additionally contains its span
+// in the form of @fluent/syntax, e.g. '"span": {"type": "Span", "start": 0, "end": 10}'.
+// Note that spans count runes, not UTF-16 code units like @fluent/syntax does.
+func MarshalJSON(node Node, withSpans bool) ([]byte, error) {
+	if !withSpans {
+		return json.Marshal(node)
+	}
+	return json.Marshal(jsonValue(reflect.ValueOf(node)))
+}
+
+// baseType is the type of the Base struct every node embeds
+var baseType = reflect.TypeOf(Base{})
+
+// jsonValue converts a value of the AST into a structure of maps and slices that contains the spans of the nodes
+func jsonValue(value reflect.Value) interface{} {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return jsonValue(value.Elem())
+	case reflect.Slice:
+		if value.IsNil() {
+			return nil
+		}
+		values := make([]interface{}, value.Len())
+		for i := range values {
+			values[i] = jsonValue(value.Index(i))
+		}
+		return values
+	case reflect.Struct:
+		fields := make(map[string]interface{}, value.NumField()+1)
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			if field.Type == baseType {
+				base := value.Field(i).Interface().(Base)
+				fields["type"] = base.Type
+				fields["span"] = map[string]interface{}{
+					"type":  "Span",
+					"start": base.Span[0],
+					"end":   base.Span[1],
+				}
+				continue
+			}
+
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" || field.PkgPath != "" {
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			fields[name] = jsonValue(value.Field(i))
+		}
+		return fields
+	default:
+		return value.Interface()
+	}
+}
@@ -16,7 +16,8 @@ func (_ *Base) node() {}
 // Resource represents the AST node of the whole FLT source (the parent node of the final AST)
 type Resource struct {
 	Base
-	Body []Node `json:"body"` // Message, Term, Comment
+	Body   []Node  `json:"body"` // Message, Term, Comment
+	Source *Source `json:"-"`    // The source the resource was parsed from; used to map spans to positions
 }
 
 // Identifier represents the identifier AST node
@@ -158,6 +159,14 @@ type Variant struct {
 // Junk represents the AST node of unparsed content
 type Junk struct {
 	Base
-	Content     string   `json:"content"`
-	Annotations []string `json:"annotations"`
+	Content     string        `json:"content"`
+	Annotations []*Annotation `json:"annotations"`
+}
+
+// Annotation represents the AST node of an error that caused content to be parsed as junk
+type Annotation struct {
+	Base
+	Code      string   `json:"code"`      // The code of the error, e.g. "E0003"
+	Arguments []string `json:"arguments"` // The arguments used to build the message
+	Message   string   `json:"message"`
 }
@@ -0,0 +1,84 @@
+package ast
+
+import (
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// Position describes a location inside a FTL source
+type Position struct {
+	Line       int // The line, starting at 1
+	Column     int // The column in runes, starting at 1
+	Offset     int // The offset in bytes, starting at 0
+	RuneOffset int // The offset in runes, starting at 0; spans use this offset
+}
+
+// String returns the position in the form 'line:column'
+func (position Position) String() string {
+	return strconv.Itoa(position.Line) + ":" + strconv.Itoa(position.Column)
+}
+
+// Source represents the FTL source a resource was parsed from.
+// It is used to map the rune offsets of spans to positions.
+type Source struct {
+	Name  string // The name of the source, e.g. the path of the file it was read from; may be empty
+	text  string
+	lines []sourceLine
+}
+
+// sourceLine holds the offsets of the first character of a line
+type sourceLine struct {
+	runeOffset int
+	byteOffset int
+}
+
+// NewSource creates a new source from its name and text
+func NewSource(name, text string) *Source {
+	lines := []sourceLine{{runeOffset: 0, byteOffset: 0}}
+	runeOffset := 0
+	for byteOffset, char := range text {
+		runeOffset++
+		if char == '\n' {
+			lines = append(lines, sourceLine{runeOffset: runeOffset, byteOffset: byteOffset + 1})
+		}
+	}
+	return &Source{
+		Name:  name,
+		text:  text,
+		lines: lines,
+	}
+}
+
+// Text returns the text of the source
+func (source *Source) Text() string {
+	return source.text
+}
+
+// Position returns the position of the given rune offset.
+// Offsets exceeding the length of the source are mapped to its end.
+func (source *Source) Position(offset uint) Position {
+	index := sort.Search(len(source.lines), func(i int) bool {
+		return source.lines[i].runeOffset > int(offset)
+	}) - 1
+	line := source.lines[index]
+
+	runeOffset, byteOffset := line.runeOffset, line.byteOffset
+	for runeOffset < int(offset) && byteOffset < len(source.text) {
+		_, size := utf8.DecodeRuneInString(source.text[byteOffset:])
+		byteOffset += size
+		runeOffset++
+	}
+
+	return Position{
+		Line:       index + 1,
+		Column:     runeOffset - line.runeOffset + 1,
+		Offset:     byteOffset,
+		RuneOffset: runeOffset,
+	}
+}
+
+// SpanPositions returns the positions of the start and the end of the given span
+func (source *Source) SpanPositions(span [2]uint) (Position, Position) {
+	return source.Position(span[0]), source.Position(span[1])
+}
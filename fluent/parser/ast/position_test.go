@@ -0,0 +1,32 @@
+package ast_test
+
+import (
+	"github.com/lus/fluent.go/fluent/parser/ast"
+	"testing"
+)
+
+func TestSourcePosition(t *testing.T) {
+	source := ast.NewSource("test.ftl", "a = b\r\nüber = ß\n\nc = d")
+
+	tests := []struct {
+		offset   uint
+		expected ast.Position
+	}{
+		{0, ast.Position{Line: 1, Column: 1, Offset: 0, RuneOffset: 0}},
+		{4, ast.Position{Line: 1, Column: 5, Offset: 4, RuneOffset: 4}},
+		{7, ast.Position{Line: 2, Column: 1, Offset: 7, RuneOffset: 7}},
+		{14, ast.Position{Line: 2, Column: 8, Offset: 15, RuneOffset: 14}},
+		{16, ast.Position{Line: 3, Column: 1, Offset: 18, RuneOffset: 16}},
+		{17, ast.Position{Line: 4, Column: 1, Offset: 19, RuneOffset: 17}},
+		{100, ast.Position{Line: 4, Column: 6, Offset: 24, RuneOffset: 22}},
+	}
+
+	for _, test := range tests {
+		if position := source.Position(test.offset); position != test.expected {
+			t.Fatalf("offset %d resolved to %+v, expected %+v", test.offset, position, test.expected)
+		}
+	}
+	if position := source.Position(14); position.String() != "2:8" {
+		t.Fatalf("unexpected string representation '%s'", position)
+	}
+}
@@ -0,0 +1,187 @@
+package ast
+
+import "reflect"
+
+// A Transformer's Transform method is invoked for each node encountered by Transform after its children were transformed.
+// It returns the node that replaces the given one; returning the given node keeps it.
+// Returning nil removes the node: it is dropped from the list it is contained in or the field holding it is set to nil.
+type Transformer interface {
+	Transform(node Node) Node
+}
+
+// TransformerFunc adapts a function to the Transformer interface
+type TransformerFunc func(node Node) Node
+
+// Transform calls f(node)
+func (f TransformerFunc) Transform(node Node) Node {
+	return f(node)
+}
+
+// Transform traverses an AST in depth-first order and replaces its nodes with the ones returned by t.
+// The children of a node are transformed before the node itself, so t always receives a node whose children are final.
+// The AST is modified in place; the (possibly replaced) root node is returned.
+// Fields that require a specific node type (like Message.Value requiring a *Pattern) keep their original node
+// if t returns a node of another type.
+func Transform(t Transformer, node Node) Node {
+	switch n := node.(type) {
+	case *Resource:
+		n.Body = transformList(t, n.Body)
+	case *Message:
+		n.ID = transformIdentifier(t, n.ID)
+		n.Value = transformPattern(t, n.Value)
+		n.Attributes = transformAttributes(t, n.Attributes)
+		n.Comment = transformComment(t, n.Comment)
+	case *Term:
+		n.ID = transformIdentifier(t, n.ID)
+		n.Value = transformPattern(t, n.Value)
+		n.Attributes = transformAttributes(t, n.Attributes)
+		n.Comment = transformComment(t, n.Comment)
+	case *Attribute:
+		n.ID = transformIdentifier(t, n.ID)
+		n.Value = transformPattern(t, n.Value)
+	case *Pattern:
+		n.Elements = transformList(t, n.Elements)
+	case *Placeable:
+		n.Expression = transformNode(t, n.Expression)
+	case *MessageReference:
+		n.ID = transformIdentifier(t, n.ID)
+		n.Attribute = transformIdentifier(t, n.Attribute)
+	case *TermReference:
+		n.ID = transformIdentifier(t, n.ID)
+		n.Attribute = transformIdentifier(t, n.Attribute)
+		n.Arguments = transformCallArguments(t, n.Arguments)
+	case *VariableReference:
+		n.ID = transformIdentifier(t, n.ID)
+	case *FunctionReference:
+		n.ID = transformIdentifier(t, n.ID)
+		n.Arguments = transformCallArguments(t, n.Arguments)
+	case *CallArguments:
+		n.Positional = transformList(t, n.Positional)
+		named := make([]*NamedArgument, 0, len(n.Named))
+		for _, argument := range n.Named {
+			if argument == nil {
+				continue
+			}
+			if transformed, ok := transformTyped(t, argument).(*NamedArgument); ok {
+				named = append(named, transformed)
+			}
+		}
+		n.Named = named
+	case *NamedArgument:
+		n.Name = transformIdentifier(t, n.Name)
+		n.Value = transformNode(t, n.Value)
+	case *SelectExpression:
+		n.Selector = transformNode(t, n.Selector)
+		variants := make([]*Variant, 0, len(n.Variants))
+		for _, variant := range n.Variants {
+			if variant == nil {
+				continue
+			}
+			if transformed, ok := transformTyped(t, variant).(*Variant); ok {
+				variants = append(variants, transformed)
+			}
+		}
+		n.Variants = variants
+	case *Variant:
+		n.Key = transformNode(t, n.Key)
+		n.Value = transformPattern(t, n.Value)
+	case *Junk:
+		annotations := make([]*Annotation, 0, len(n.Annotations))
+		for _, annotation := range n.Annotations {
+			if annotation == nil {
+				continue
+			}
+			if transformed, ok := transformTyped(t, annotation).(*Annotation); ok {
+				annotations = append(annotations, transformed)
+			}
+		}
+		n.Annotations = annotations
+	}
+
+	return t.Transform(node)
+}
+
+// transformNode transforms a node held by an untyped field
+func transformNode(t Transformer, node Node) Node {
+	if node == nil {
+		return nil
+	}
+	return Transform(t, node)
+}
+
+// transformList transforms every node of a list and drops the removed ones
+func transformList(t Transformer, nodes []Node) []Node {
+	transformed := make([]Node, 0, len(nodes))
+	for _, node := range nodes {
+		if node == nil {
+			continue
+		}
+		if result := Transform(t, node); result != nil {
+			transformed = append(transformed, result)
+		}
+	}
+	return transformed
+}
+
+// transformTyped transforms a node held by a typed field or list.
+// If the replacement is not nil and has another type than the original node, the original node is returned.
+func transformTyped(t Transformer, node Node) Node {
+	result := Transform(t, node)
+	if result == nil {
+		return nil
+	}
+	if reflect.TypeOf(result) != reflect.TypeOf(node) {
+		return node
+	}
+	return result
+}
+
+// transformIdentifier transforms an identifier held by a typed field
+func transformIdentifier(t Transformer, identifier *Identifier) *Identifier {
+	if identifier == nil {
+		return nil
+	}
+	result, _ := transformTyped(t, identifier).(*Identifier)
+	return result
+}
+
+// transformPattern transforms a pattern held by a typed field
+func transformPattern(t Transformer, pattern *Pattern) *Pattern {
+	if pattern == nil {
+		return nil
+	}
+	result, _ := transformTyped(t, pattern).(*Pattern)
+	return result
+}
+
+// transformComment transforms the comment attached to a message or term
+func transformComment(t Transformer, comment *Comment) *Comment {
+	if comment == nil {
+		return nil
+	}
+	result, _ := transformTyped(t, comment).(*Comment)
+	return result
+}
+
+// transformCallArguments transforms the arguments of a term or function reference
+func transformCallArguments(t Transformer, arguments *CallArguments) *CallArguments {
+	if arguments == nil {
+		return nil
+	}
+	result, _ := transformTyped(t, arguments).(*CallArguments)
+	return result
+}
+
+// transformAttributes transforms the attributes of a message or term and drops the removed ones
+func transformAttributes(t Transformer, attributes []*Attribute) []*Attribute {
+	transformed := make([]*Attribute, 0, len(attributes))
+	for _, attribute := range attributes {
+		if attribute == nil {
+			continue
+		}
+		if result, ok := transformTyped(t, attribute).(*Attribute); ok {
+			transformed = append(transformed, result)
+		}
+	}
+	return transformed
+}
@@ -26,6 +26,7 @@ const (
 	TypeSelectExpression  nodeType = "SelectExpression"
 	TypeVariant           nodeType = "Variant"
 	TypeJunk              nodeType = "Junk"
+	TypeAnnotation        nodeType = "Annotation"
 )
 
 // IsEntry checks if a type represents an entry of a resource
@@ -0,0 +1,150 @@
+package ast
+
+// A Visitor's Visit method is invoked for each node encountered by Walk.
+// If the result visitor w is not nil, Walk visits each of the children of node with the visitor w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses an AST in depth-first order, mirroring go/ast.Walk.
+// It starts by calling v.Visit(node); node must not be nil.
+// If the visitor w returned by v.Visit(node) is not nil, Walk is invoked recursively with visitor w for each of the
+// non-nil children of node, followed by a call of w.Visit(nil).
+// Children are visited in the order of the fields of the node.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Resource:
+		walkList(v, n.Body)
+	case *Message:
+		walkEntry(v, n.ID, n.Value, n.Attributes, n.Comment)
+	case *Term:
+		walkEntry(v, n.ID, n.Value, n.Attributes, n.Comment)
+	case *Attribute:
+		if n.ID != nil {
+			Walk(v, n.ID)
+		}
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+	case *Pattern:
+		walkList(v, n.Elements)
+	case *Placeable:
+		if n.Expression != nil {
+			Walk(v, n.Expression)
+		}
+	case *MessageReference:
+		if n.ID != nil {
+			Walk(v, n.ID)
+		}
+		if n.Attribute != nil {
+			Walk(v, n.Attribute)
+		}
+	case *TermReference:
+		if n.ID != nil {
+			Walk(v, n.ID)
+		}
+		if n.Attribute != nil {
+			Walk(v, n.Attribute)
+		}
+		if n.Arguments != nil {
+			Walk(v, n.Arguments)
+		}
+	case *VariableReference:
+		if n.ID != nil {
+			Walk(v, n.ID)
+		}
+	case *FunctionReference:
+		if n.ID != nil {
+			Walk(v, n.ID)
+		}
+		if n.Arguments != nil {
+			Walk(v, n.Arguments)
+		}
+	case *CallArguments:
+		walkList(v, n.Positional)
+		for _, named := range n.Named {
+			if named != nil {
+				Walk(v, named)
+			}
+		}
+	case *NamedArgument:
+		if n.Name != nil {
+			Walk(v, n.Name)
+		}
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+	case *SelectExpression:
+		if n.Selector != nil {
+			Walk(v, n.Selector)
+		}
+		for _, variant := range n.Variants {
+			if variant != nil {
+				Walk(v, variant)
+			}
+		}
+	case *Variant:
+		if n.Key != nil {
+			Walk(v, n.Key)
+		}
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+	case *Junk:
+		for _, annotation := range n.Annotations {
+			if annotation != nil {
+				Walk(v, annotation)
+			}
+		}
+	}
+
+	v.Visit(nil)
+}
+
+// walkList walks every non-nil node of a list
+func walkList(v Visitor, nodes []Node) {
+	for _, node := range nodes {
+		if node != nil {
+			Walk(v, node)
+		}
+	}
+}
+
+// walkEntry walks the children of a message or term
+func walkEntry(v Visitor, id *Identifier, value *Pattern, attributes []*Attribute, comment *Comment) {
+	if id != nil {
+		Walk(v, id)
+	}
+	if value != nil {
+		Walk(v, value)
+	}
+	for _, attribute := range attributes {
+		if attribute != nil {
+			Walk(v, attribute)
+		}
+	}
+	if comment != nil {
+		Walk(v, comment)
+	}
+}
+
+// inspector adapts a function to the Visitor interface
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses an AST in depth-first order, mirroring go/ast.Inspect.
+// It starts by calling f(node); node must not be nil.
+// If f returns true, Inspect invokes f recursively for each of the non-nil children of node, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
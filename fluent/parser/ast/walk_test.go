@@ -0,0 +1,150 @@
+package ast_test
+
+import (
+	"github.com/lus/fluent.go/fluent/parser"
+	"github.com/lus/fluent.go/fluent/parser/ast"
+	"reflect"
+	"testing"
+)
+
+const source = `# Comment
+message = Hello, { $name }!
+    .title = { -brand(case: "short") }
+select = { NUMBER($count) ->
+    [one] One { other }
+   *[other] Many
+}
+-brand = Fluent
+`
+
+func parse(t *testing.T) *ast.Resource {
+	resource, errs := parser.New(source).Parse()
+	if len(errs) > 0 {
+		t.Fatalf("could not parse test resource: %v", errs)
+	}
+	return resource
+}
+
+func TestInspect(t *testing.T) {
+	var variables, messageRefs, termRefs, functions []string
+	ast.Inspect(parse(t), func(node ast.Node) bool {
+		switch n := node.(type) {
+		case *ast.VariableReference:
+			variables = append(variables, n.ID.Name)
+		case *ast.MessageReference:
+			messageRefs = append(messageRefs, n.ID.Name)
+		case *ast.TermReference:
+			termRefs = append(termRefs, n.ID.Name)
+		case *ast.FunctionReference:
+			functions = append(functions, n.ID.Name)
+		}
+		return true
+	})
+
+	if !reflect.DeepEqual(variables, []string{"name", "count"}) {
+		t.Fatalf("unexpected variables %v", variables)
+	}
+	if !reflect.DeepEqual(messageRefs, []string{"other"}) {
+		t.Fatalf("unexpected message references %v", messageRefs)
+	}
+	if !reflect.DeepEqual(termRefs, []string{"brand"}) {
+		t.Fatalf("unexpected term references %v", termRefs)
+	}
+	if !reflect.DeepEqual(functions, []string{"NUMBER"}) {
+		t.Fatalf("unexpected functions %v", functions)
+	}
+}
+
+// depthVisitor records the maximum depth of the visited nodes
+type depthVisitor struct {
+	depth int
+	max   *int
+}
+
+func (v depthVisitor) Visit(node ast.Node) ast.Visitor {
+	if node == nil {
+		return nil
+	}
+	if v.depth > *v.max {
+		*v.max = v.depth
+	}
+	// Do not descend into attributes
+	if _, ok := node.(*ast.Attribute); ok {
+		return nil
+	}
+	return depthVisitor{depth: v.depth + 1, max: v.max}
+}
+
+func TestWalk(t *testing.T) {
+	max := 0
+	ast.Walk(depthVisitor{depth: 0, max: &max}, parse(t))
+
+	// Resource > Message > Pattern > Placeable > SelectExpression > Variant > Pattern > Placeable > MessageReference > Identifier
+	if max != 9 {
+		t.Fatalf("unexpected maximum depth %d", max)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	resource := parse(t)
+
+	// Rename a variable, replace a text, remove the comment and drop a variant
+	result := ast.Transform(ast.TransformerFunc(func(node ast.Node) ast.Node {
+		switch n := node.(type) {
+		case *ast.VariableReference:
+			if n.ID.Name == "name" {
+				n.ID.Name = "user"
+			}
+		case *ast.Text:
+			if n.Value == "Hello, " {
+				return &ast.Text{Base: n.Base, Value: "Hi, "}
+			}
+		case *ast.Comment:
+			return nil
+		case *ast.Variant:
+			if !n.Default {
+				return nil
+			}
+		case *ast.Identifier:
+			// Replacements of the wrong type are ignored for typed fields
+			if n.Name == "title" {
+				return &ast.Text{Base: n.Base, Value: "title"}
+			}
+		}
+		return node
+	}), resource)
+
+	if result != resource {
+		t.Fatal("expected the root node to be kept")
+	}
+
+	message := resource.Body[0].(*ast.Message)
+	if message.Comment != nil {
+		t.Fatal("expected the comment to be removed")
+	}
+	if text := message.Value.Elements[0].(*ast.Text); text.Value != "Hi, " {
+		t.Fatalf("unexpected text '%s'", text.Value)
+	}
+	if message.Attributes[0].ID.Name != "title" {
+		t.Fatal("expected the attribute identifier to be kept")
+	}
+	if ref := message.Value.Elements[1].(*ast.Placeable).Expression.(*ast.VariableReference); ref.ID.Name != "user" {
+		t.Fatalf("unexpected variable '%s'", ref.ID.Name)
+	}
+
+	selectExpr := resource.Body[1].(*ast.Message).Value.Elements[0].(*ast.Placeable).Expression.(*ast.SelectExpression)
+	if len(selectExpr.Variants) != 1 || !selectExpr.Variants[0].Default {
+		t.Fatalf("expected only the default variant to remain, got %d variants", len(selectExpr.Variants))
+	}
+
+	// Removing entries from the resource
+	ast.Transform(ast.TransformerFunc(func(node ast.Node) ast.Node {
+		if _, ok := node.(*ast.Term); ok {
+			return nil
+		}
+		return node
+	}), resource)
+	if len(resource.Body) != 2 {
+		t.Fatalf("expected the term to be removed, got %d entries", len(resource.Body))
+	}
+}
@@ -1,22 +1,106 @@
 package parser
 
-import "fmt"
+import (
+	"fmt"
+	"github.com/lus/fluent.go/fluent/parser/ast"
+)
 
-// Error represents an error raised by the parser
+// Error represents an error raised by the parser.
+// Its code and message match the ones of the Fluent reference parser (@fluent/syntax).
 type Error struct {
-	Span    [2]uint
-	Message string
+	Span      [2]uint
+	Code      string   // The code of the error, e.g. "E0003"
+	Arguments []string // The arguments used to build the message, e.g. the expected token
+	Message   string
+	source    *ast.Source
 }
 
-// Error turns the error into a string
+// Error turns the error into a string.
+// If the source the error was raised in has a name, the name and the position are prepended, e.g. 'messages.ftl:12:5: ...'.
 func (err *Error) Error() string {
+	if err.source != nil && err.source.Name != "" {
+		return fmt.Sprintf("%s:%s: %s", err.source.Name, err.Position(), err.Message)
+	}
 	return err.Message
 }
 
-// newError creates a new error
-func newError(start, end uint, msgFormat string, replacements ...interface{}) *Error {
+// SourceName returns the name of the source the error was raised in; it is empty if the source has no name
+func (err *Error) SourceName() string {
+	if err.source == nil {
+		return ""
+	}
+	return err.source.Name
+}
+
+// Position returns the position of the start of the error span.
+// If the error was not returned by Parser.Parse, only the rune offset is set.
+func (err *Error) Position() ast.Position {
+	if err.source == nil {
+		return ast.Position{RuneOffset: int(err.Span[0])}
+	}
+	return err.source.Position(err.Span[0])
+}
+
+// Annotation converts the error into an annotation AST node as attached to junk.
+// Like the ones of the reference parser, its span is empty and located at the end of the error span.
+func (err *Error) Annotation() *ast.Annotation {
+	return &ast.Annotation{
+		Base: ast.Base{
+			Type: ast.TypeAnnotation,
+			Span: [2]uint{err.Span[1], err.Span[1]},
+		},
+		Code:      err.Code,
+		Arguments: err.Arguments,
+		Message:   err.Message,
+	}
+}
+
+// errorMessages holds the message formats of the error codes; the arguments of an error replace the verbs in order
+var errorMessages = map[string]string{
+	"E0001": "Generic error",
+	"E0002": "Expected an entry start",
+	"E0003": "Expected token: \"%s\"",
+	"E0004": "Expected a character from range: \"%s\"",
+	"E0005": "Expected message \"%s\" to have a value or attributes",
+	"E0006": "Expected term \"-%s\" to have a value",
+	"E0007": "Keyword cannot end with a whitespace",
+	"E0008": "The callee has to be an upper-case identifier or a term",
+	"E0009": "The argument name has to be a simple identifier",
+	"E0010": "Expected one of the variants to be marked as default (*)",
+	"E0011": "Expected at least one variant after \"->\"",
+	"E0012": "Expected value",
+	"E0013": "Expected variant key",
+	"E0014": "Expected literal",
+	"E0015": "Only one variant can be marked as default (*)",
+	"E0016": "Message references cannot be used as selectors",
+	"E0017": "Terms cannot be used as selectors",
+	"E0018": "Attributes of messages cannot be used as selectors",
+	"E0019": "Attributes of terms cannot be used as placeables",
+	"E0020": "Unterminated string expression",
+	"E0021": "Positional arguments must not follow named arguments",
+	"E0022": "Named arguments must be unique",
+	"E0024": "Cannot access variants of a message.",
+	"E0025": "Unknown escape sequence: \\%s.",
+	"E0026": "Invalid Unicode escape sequence: %s.",
+	"E0027": "Unbalanced closing brace in TextElement.",
+	"E0028": "Expected an inline expression",
+	"E0029": "Expected simple expression as selector",
+}
+
+// newError creates a new error with the given code and arguments
+func newError(start, end uint, code string, arguments ...string) *Error {
+	replacements := make([]interface{}, len(arguments))
+	for i, argument := range arguments {
+		replacements[i] = argument
+	}
+	if arguments == nil {
+		arguments = []string{}
+	}
 	return &Error{
-		Span:    [2]uint{start, end},
-		Message: fmt.Sprintf(msgFormat, replacements...),
+		Span:      [2]uint{start, end},
+		Code:      code,
+		Arguments: arguments,
+		Message:   fmt.Sprintf(errorMessages[code], replacements...),
+		source:    nil,
 	}
 }
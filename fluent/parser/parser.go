@@ -11,12 +11,22 @@ const maxInt = int(^uint(0) >> 1)
 
 // Parser is used to parse a FTL source into an AST
 type Parser struct {
-	str *stream
+	str    *stream
+	source *ast.Source
 }
 
 // New creates a new FTL parser from a source string
 func New(source string) *Parser {
-	return &Parser{str: newStream(source)}
+	return NewNamed("", source)
+}
+
+// NewNamed creates a new FTL parser from a source string and its name, e.g. the path of the file it was read from.
+// The name is recorded on the parsed resource and on the errors raised while parsing it.
+func NewNamed(name, source string) *Parser {
+	return &Parser{
+		str:    newStream(source),
+		source: ast.NewSource(name, source),
+	}
 }
 
 // Parse parses the underlying FTL source string into an AST.
@@ -38,7 +48,7 @@ func (parser *Parser) Parse() (*ast.Resource, []*Error) {
 			if pErr, ok := err.(*Error); ok {
 				errors = append(errors, pErr)
 			} else {
-				errors = append(errors, newError(0, 0, err.Error()))
+				errors = append(errors, &Error{Span: [2]uint{0, 0}, Code: "E0001", Arguments: []string{}, Message: err.Error(), source: nil})
 			}
 		}
 
@@ -70,13 +80,19 @@ func (parser *Parser) Parse() (*ast.Resource, []*Error) {
 		entries = append(entries, entry)
 	}
 
+	// Attach the source to the errors to be able to map their spans to positions
+	for _, err := range errors {
+		err.source = parser.source
+	}
+
 	// Build the resource AST node
 	return &ast.Resource{
 		Base: ast.Base{
 			Type: ast.TypeResource,
 			Span: [2]uint{0, uint(parser.str.SrcLen())},
 		},
-		Body: entries,
+		Body:   entries,
+		Source: parser.source,
 	}, errors
 }
 
@@ -118,17 +134,32 @@ func (parser *Parser) parseEntryOrJunk() (ast.Node, error) {
 	})
 	parser.str.Skip(cur)
 
-	// Extract the junk content
-	nextEntryStart := parser.str.CurrentCursorPos()
-	if nextEntryStart == len(parser.str.Src()) {
-		nextEntryStart--
+	// Extract the junk content; it ends right before the next entry (including the EOL preceding it)
+	nextEntryStart := parser.str.CurrentCursorPos() + 1
+	if nextEntryStart > len(parser.str.Src()) {
+		nextEntryStart = len(parser.str.Src())
 	}
-	content := parser.str.Src()[start : nextEntryStart+1]
+	content := parser.str.Src()[start:nextEntryStart]
 
 	// Build the junk AST node
-	annotation := ""
-	if err != nil {
-		annotation = err.Error()
+	annotations := []*ast.Annotation{}
+	if pErr, ok := err.(*Error); ok {
+		// Like the reference parser, the annotation points to the position the error was raised at within the junk
+		annotation := pErr.Annotation()
+		if annotation.Span[0] > uint(nextEntryStart) {
+			annotation.Span = [2]uint{uint(nextEntryStart), uint(nextEntryStart)}
+		}
+		annotations = append(annotations, annotation)
+	} else if err != nil {
+		annotations = append(annotations, &ast.Annotation{
+			Base: ast.Base{
+				Type: ast.TypeAnnotation,
+				Span: [2]uint{uint(start), uint(start)},
+			},
+			Code:      "E0001",
+			Arguments: []string{},
+			Message:   err.Error(),
+		})
 	}
 	return &ast.Junk{
 		Base: ast.Base{
@@ -136,7 +167,7 @@ func (parser *Parser) parseEntryOrJunk() (ast.Node, error) {
 			Span: [2]uint{uint(start), uint(nextEntryStart)},
 		},
 		Content:     string(content),
-		Annotations: []string{annotation},
+		Annotations: annotations,
 	}, err
 }
 
@@ -282,7 +313,7 @@ func (parser *Parser) parseTerm() (*ast.Term, error) {
 		return nil, err
 	}
 	if value == nil {
-		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "a pattern is required for terms")
+		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0006", id.Name)
 	}
 
 	// Parse the attributes
@@ -342,7 +373,7 @@ func (parser *Parser) parseMessage() (*ast.Message, error) {
 
 	// Raise an error if no attributes and no pattern value could be parsed
 	if value == nil && len(attributes) == 0 {
-		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "message entries may not be completely blank")
+		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0005", id.Name)
 	}
 
 	// Build the message AST node
@@ -432,7 +463,7 @@ func (parser *Parser) parsePattern(block bool) (*ast.Pattern, error) {
 			elements = append(elements, placeable)
 		} else if peek == '}' {
 			pos := uint(parser.str.CurrentCursorPos())
-			return nil, newError(pos, pos, "unexpected '}'")
+			return nil, newError(pos, pos, "E0027")
 		} else if peek == EOL {
 			// Validate the indent and first character of the next line and skip all blank characters if the text block continues
 			indentStart := uint(parser.str.CurrentCursorPos())
@@ -613,24 +644,27 @@ func (parser *Parser) parseExpression() (ast.Node, error) {
 	if !(parser.str.Peek() == '-' && parser.str.PeekNth(1) == '>') {
 		// Term attribute references are not allowed in placeables
 		if term, ok := selector.(*ast.TermReference); ok && term.Attribute != nil {
-			return nil, newError(start, uint(parser.str.CurrentCursorPos()), "term attribute references are not allowed in placeables")
+			return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0019")
 		}
 		return selector, nil
 	}
 
 	// Message references may not be used as select expression selectors
-	if _, ok := selector.(*ast.MessageReference); ok {
-		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "message references are not allowed as selectors")
+	if message, ok := selector.(*ast.MessageReference); ok {
+		if message.Attribute != nil {
+			return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0018")
+		}
+		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0016")
 	}
 
 	// Other placeables may not be used as select expression selectors
 	if _, ok := selector.(*ast.Placeable); ok {
-		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "placeables are not allowed as selectors")
+		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0029")
 	}
 
 	// Term references without an attribute may not be used as select expression selectors
 	if term, ok := selector.(*ast.TermReference); ok && term.Attribute == nil {
-		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "normal term references are not allowed as selectors; consider using a term attribute reference instead")
+		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0017")
 	}
 
 	// Skip the '->'
@@ -743,7 +777,7 @@ func (parser *Parser) parseInlineExpression() (ast.Node, error) {
 
 	// We'll parse a message or function reference. In both cases a valid identifier has to be present
 	if !isIdentifierStart(peek) {
-		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "no inline expression")
+		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0028")
 	}
 
 	// Parse the actual identifier
@@ -759,7 +793,7 @@ func (parser *Parser) parseInlineExpression() (ast.Node, error) {
 	if first == '(' {
 		// Function names have to be all-uppercase
 		if hasLowercase([]rune(identifier.Name)) {
-			return nil, newError(idStart, uint(parser.str.CurrentCursorPos()), "function names only may have uppercase letters")
+			return nil, newError(idStart, uint(parser.str.CurrentCursorPos()), "E0008")
 		}
 
 		// Blank content before the '(' is ignored
@@ -837,12 +871,12 @@ func (parser *Parser) parseCallArguments() (*ast.CallArguments, error) {
 		// Ensure named arguments are only provided once and positional arguments are not specified after named ones
 		if namedArg, ok := argument.(*ast.NamedArgument); ok {
 			if names[namedArg.Name.Name] {
-				return nil, newError(argStart, uint(parser.str.CurrentCursorPos()), "argument name already satisfied")
+				return nil, newError(argStart, uint(parser.str.CurrentCursorPos()), "E0022")
 			}
 			names[namedArg.Name.Name] = true
 			named = append(named, namedArg)
 		} else if len(named) > 0 {
-			return nil, newError(argStart, uint(parser.str.CurrentCursorPos()), "positional arguments may not follow named ones")
+			return nil, newError(argStart, uint(parser.str.CurrentCursorPos()), "E0021")
 		} else {
 			positional = append(positional, argument)
 		}
@@ -896,7 +930,7 @@ func (parser *Parser) parseCallArgument() (ast.Node, error) {
 
 	// The name of a name argument has to be a valid identifier (message reference expression with no attributes)
 	if exp, ok := expression.(*ast.MessageReference); !ok || exp.Attribute != nil {
-		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "argument name is no simple identifier")
+		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0009")
 	}
 
 	// Skip the ':' and any blank content after it
@@ -939,7 +973,7 @@ func (parser *Parser) parseVariants() ([]*ast.Variant, error) {
 		isDefault := false
 		if peek == '*' {
 			if setDefault {
-				return nil, newError(variantStart, variantStart, "only one default select variant is allowed")
+				return nil, newError(variantStart, variantStart, "E0015")
 			}
 			setDefault = true
 			isDefault = true
@@ -975,7 +1009,7 @@ func (parser *Parser) parseVariants() ([]*ast.Variant, error) {
 			return nil, err
 		}
 		if pattern == nil {
-			return nil, newError(variantStart, uint(parser.str.CurrentCursorPos()), "a value for the select variant is required")
+			return nil, newError(variantStart, uint(parser.str.CurrentCursorPos()), "E0012")
 		}
 
 		// Build and append a new variant node
@@ -1002,12 +1036,12 @@ func (parser *Parser) parseVariants() ([]*ast.Variant, error) {
 
 	// Ensure at least one variant was provided
 	if len(variants) == 0 {
-		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "at least one variant is required")
+		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0011")
 	}
 
 	// A default variant is also required
 	if !setDefault {
-		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "a default variant is required")
+		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0010")
 	}
 
 	return variants, nil
@@ -1020,7 +1054,7 @@ func (parser *Parser) parseVariantKey() (ast.Node, error) {
 	// An EOL is not allowed
 	if peek == EOL {
 		pos := uint(parser.str.CurrentCursorPos())
-		return nil, newError(pos, pos, "no variant key was given")
+		return nil, newError(pos, pos, "E0013")
 	}
 
 	// Parse a number if the variant key starts with a digit or '-'
@@ -1084,7 +1118,7 @@ func (parser *Parser) parseAttribute() (*ast.Attribute, error) {
 		return nil, err
 	}
 	if value == nil {
-		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "a value for the attribute is required")
+		return nil, newError(start, uint(parser.str.CurrentCursorPos()), "E0012")
 	}
 
 	// Build the attribute AST node
@@ -1113,7 +1147,7 @@ func (parser *Parser) parseLiteral() (ast.Node, error) {
 	}
 
 	pos := uint(parser.str.CurrentCursorPos())
-	return nil, newError(pos, pos, "invalid literal beginning (-, 0-9 or \" required)")
+	return nil, newError(pos, pos, "E0014")
 }
 
 // parseNumber parses a number node
@@ -1144,7 +1178,7 @@ func (parser *Parser) parseNumber() (*ast.NumberLiteral, error) {
 		}
 		if !hasDecimal {
 			pos := uint(parser.str.CurrentCursorPos())
-			return nil, newError(pos, pos, "no numbers after the decimal point")
+			return nil, newError(pos, pos, "E0004", "0-9")
 		}
 	}
 
@@ -1181,10 +1215,12 @@ func (parser *Parser) parseString() (*ast.StringLiteral, error) {
 		}
 	}
 
-	// A closing '"' is required
-	if err := parser.expect('"'); err != nil {
-		return nil, err
+	// A closing '"' is required on the same line
+	if parser.str.Peek() != '"' {
+		pos := uint(parser.str.CurrentCursorPos())
+		return nil, newError(pos, pos, "E0020")
 	}
+	parser.str.Skip(1)
 
 	// Build the string AST node
 	return &ast.StringLiteral{
@@ -1213,7 +1249,7 @@ func (parser *Parser) parseEscapeSequence() (string, error) {
 		return parser.parseUnicodeEscapeSequence(true)
 	default:
 		pos := uint(parser.str.CurrentCursorPos())
-		return "", newError(pos, pos, "unknown escape sequence")
+		return "", newError(pos, pos, "E0025", string(peek))
 	}
 }
 
@@ -1238,7 +1274,10 @@ func (parser *Parser) parseUnicodeEscapeSequence(sixDigits bool) (string, error)
 		peek := parser.str.Peek()
 		if !((peek >= '0' && peek <= '9') || (peek >= 'a' && peek <= 'f') || (peek >= 'A' && peek <= 'F')) {
 			pos := uint(parser.str.CurrentCursorPos())
-			return "", newError(pos, pos, "no valid HEX character (0-9a-fA-F)")
+			if peek != EOF {
+				raw += string(peek)
+			}
+			return "", newError(pos, pos, "E0026", raw)
 		}
 		raw += string(parser.str.Consume())
 	}
@@ -1255,7 +1294,7 @@ func (parser *Parser) parseIdentifier() (*ast.Identifier, error) {
 	// Validate and append the starting character (a-zA-Z only)
 	startChar := parser.str.Peek()
 	if !isIdentifierStart(startChar) {
-		return nil, newError(start, start, "invalid identifier start character (only a-zA-Z are allowed)")
+		return nil, newError(start, start, "E0004", "a-zA-Z")
 	}
 	id += string(startChar)
 	parser.str.Skip(1)
@@ -1346,7 +1385,11 @@ func (parser *Parser) expect(runes ...rune) error {
 	for _, char := range runes {
 		if parser.str.PeekNth(found) != char {
 			pos := uint(parser.str.CurrentCursorPos())
-			return newError(pos, pos, "'%s' expected", string(char))
+			// Line endings are represented by the symbol for newline (U+2424) like the reference parser does
+			if char == EOL {
+				return newError(pos, pos, "E0003", "\u2424")
+			}
+			return newError(pos, pos, "E0003", string(char))
 		}
 		found++
 	}
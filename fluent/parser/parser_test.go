@@ -12,9 +12,28 @@ import (
 )
 
 func TestFixtures(t *testing.T) {
-	// Collect the file names (without extension) from the fixtures in the '../../test/fixtures' directory
+	// The fixtures of the specification in '../../test/fixtures' contain neither spans nor junk annotations,
+	// so the annotations of the parsed junk are dropped before comparing them.
+	// The fixture in '../../test/annotations' contains both and is compared as a whole.
+	testFixtures(t, filepath.Join("../../test", "fixtures"), false)
+	testFixtures(t, filepath.Join("../../test", "annotations"), true)
+}
+
+func TestReferenceFixtures(t *testing.T) {
+	// The structure fixtures of the reference parser contain spans and junk annotations and are imported manually
+	directory := filepath.Join("../../test", "fixtures_structure")
+	if matches, _ := filepath.Glob(filepath.Join(directory, "*.ftl")); len(matches) == 0 {
+		t.Skip("the structure fixtures of @fluent/syntax have not been imported, see test/fixtures_structure/README.md")
+	}
+	testFixtures(t, directory, true)
+}
+
+// testFixtures parses every FTL fixture in the given directory and compares the result to the JSON fixture of the same name.
+// If withAnnotations is false, the annotations of junk entries are not compared; otherwise the spans are compared too.
+func testFixtures(t *testing.T, directory string, withAnnotations bool) {
+	// Collect the file names (without extension) from the fixtures in the directory
 	var fileNames []string
-	filepath.Walk(filepath.Join("../../test", "fixtures"), func(path string, info fs.FileInfo, err error) error {
+	filepath.Walk(directory, func(path string, info fs.FileInfo, err error) error {
 		if info == nil || info.IsDir() {
 			return nil
 		}
@@ -26,7 +45,7 @@ func TestFixtures(t *testing.T) {
 
 	for _, fileName := range fileNames {
 		// Read the FTL input of the fixture
-		input, err := ioutil.ReadFile(filepath.Join("../../test", "fixtures", fileName+".ftl"))
+		input, err := ioutil.ReadFile(filepath.Join(directory, fileName+".ftl"))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -36,16 +55,10 @@ func TestFixtures(t *testing.T) {
 		if resource == nil {
 			t.Fatal("parsed resource is nil")
 		}
-		for _, entry := range resource.Body {
-			// Remove junk annotations as these are excluded in the fixtures
-			if junk, ok := entry.(*ast.Junk); ok {
-				junk.Annotations = []string{}
-			}
-		}
 
 		// Marshal the parsed AST into JSON and unmarshal it into a map
 		// This is simply done to not include any 3rd party dependencies that directly perform struct -> map marshalling
-		resourceJson, err := json.Marshal(resource)
+		resourceJson, err := ast.MarshalJSON(resource, withAnnotations)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -55,7 +68,7 @@ func TestFixtures(t *testing.T) {
 		}
 
 		// Read the expected AST from the fixture and marshal it into a map too
-		expectedOutputJson, err := ioutil.ReadFile(filepath.Join("../../test", "fixtures", fileName+".json"))
+		expectedOutputJson, err := ioutil.ReadFile(filepath.Join(directory, fileName+".json"))
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -64,6 +77,15 @@ func TestFixtures(t *testing.T) {
 			t.Fatal(err)
 		}
 
+		if !withAnnotations {
+			body, _ := resourceMap["body"].([]interface{})
+			for _, entry := range body {
+				if junk, _ := entry.(map[string]interface{}); junk["type"] == string(ast.TypeJunk) {
+					junk["annotations"] = []interface{}{}
+				}
+			}
+		}
+
 		// Both maps have to match in order to pass the test
 		matches := reflect.DeepEqual(resourceMap, expectedOutputMap)
 		if !matches {
@@ -71,3 +93,48 @@ func TestFixtures(t *testing.T) {
 		}
 	}
 }
+
+func TestErrorPositions(t *testing.T) {
+	resource, errs := New("valid = Välue\r\n\r\nkey = { über\nnext = Value\n").Parse()
+	if len(errs) != 1 {
+		t.Fatalf("expected a single error, got %v", errs)
+	}
+
+	position := errs[0].Position()
+	if position.Line != 3 || position.Column != 9 || position.RuneOffset != 25 || position.Offset != 26 {
+		t.Fatalf("unexpected error position %+v", position)
+	}
+	if resource.Source == nil || resource.Source.Position(errs[0].Span[0]) != position {
+		t.Fatal("expected the resource source to map the error span to the same position")
+	}
+}
+
+func TestSpansJSON(t *testing.T) {
+	resource, _ := New("key = Value").Parse()
+
+	withoutSpans, err := ast.MarshalJSON(resource, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(withoutSpans), "span") {
+		t.Fatalf("unexpected spans in %s", withoutSpans)
+	}
+
+	withSpans, err := ast.MarshalJSON(resource, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resourceMap := make(map[string]interface{})
+	if err := json.Unmarshal(withSpans, &resourceMap); err != nil {
+		t.Fatal(err)
+	}
+	message := resourceMap["body"].([]interface{})[0].(map[string]interface{})
+	span := message["span"].(map[string]interface{})
+	if message["type"] != "Message" || span["type"] != "Span" || span["start"] != 0.0 || span["end"] != 11.0 {
+		t.Fatalf("unexpected message JSON %v", message)
+	}
+	value := message["value"].(map[string]interface{})
+	if valueSpan := value["span"].(map[string]interface{}); valueSpan["start"] != 6.0 || valueSpan["end"] != 11.0 {
+		t.Fatalf("unexpected pattern span %v", valueSpan)
+	}
+}
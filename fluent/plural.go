@@ -0,0 +1,135 @@
+package fluent
+
+import (
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"strings"
+	"sync"
+)
+
+var pluralStrings = map[plural.Form]string{
+	plural.Other: "other",
+	plural.Zero:  "zero",
+	plural.One:   "one",
+	plural.Two:   "two",
+	plural.Few:   "few",
+	plural.Many:  "many",
+}
+
+// PluralRule selects the plural category ('zero', 'one', 'two', 'few', 'many' or 'other') of a number.
+// The number is already rounded according to its format options; ordinal reports whether ordinal rules are requested.
+type PluralRule func(number *NumberValue, ordinal bool) string
+
+// customPluralRules holds the plural rules registered using RegisterPluralRule
+var (
+	customPluralRules      = make(map[language.Tag]PluralRule)
+	customPluralRulesMutex sync.RWMutex
+)
+
+// RegisterPluralRule registers a plural rule for a locale, e.g. for languages CLDR provides no plural rules for.
+// Custom rules take precedence over the CLDR rules and apply to all bundles using the locale or a more specific one.
+// Passing a nil rule removes the registered rule again.
+func RegisterPluralRule(locale language.Tag, rule PluralRule) {
+	customPluralRulesMutex.Lock()
+	defer customPluralRulesMutex.Unlock()
+	if rule == nil {
+		delete(customPluralRules, locale)
+		return
+	}
+	customPluralRules[locale] = rule
+}
+
+// lookupPluralRule returns the custom plural rule registered for the given locale
+func lookupPluralRule(locale language.Tag) PluralRule {
+	customPluralRulesMutex.RLock()
+	defer customPluralRulesMutex.RUnlock()
+	return customPluralRules[locale]
+}
+
+// pluralLocales expands the locales of a bundle into the locales to look up plural rules for, in order of precedence.
+// Every locale is followed by its parents (e.g. 'sr-Latn-RS', 'sr-Latn') and its base language ('sr').
+func pluralLocales(locales []language.Tag) []language.Tag {
+	seen := make(map[language.Tag]bool)
+	var result []language.Tag
+	add := func(locale language.Tag) {
+		if locale != language.Und && !seen[locale] {
+			seen[locale] = true
+			result = append(result, locale)
+		}
+	}
+
+	for _, locale := range locales {
+		for current := locale; current != language.Und; current = current.Parent() {
+			add(current)
+		}
+		if base, confidence := locale.Base(); confidence != language.No {
+			if parsed, err := language.Compose(base); err == nil {
+				add(parsed)
+			}
+		}
+	}
+	return result
+}
+
+// cldrPluralLanguages holds the languages the CLDR version shipped with x/text (32) defines plural rules for.
+// x/text maps all other languages to the root locale, which always selects 'other'.
+// The list has to be updated together with x/text; TestCLDRPluralLanguages fails if it does not match x/text anymore.
+var cldrPluralLanguages = func() map[string]bool {
+	languages := make(map[string]bool)
+	for _, base := range strings.Fields(`
+	af ak am ar ars as asa ast az be bem bez bg bh bm bn bo br brx bs ca ce cgg chr ckb cs cy da de
+	dsb dv dz ee el en eo es et eu fa ff fi fil fo fr fur fy ga gd gl gsw gu guw gv ha haw he hi hr
+	hsb hu hy id ig ii in io is it iu iw ja jbo jgo ji jmc jv jw ka kab kaj kcg kde kea kk kkj kl km
+	kn ko ks ksb ksh ku kw ky lag lb lg lkt ln lo lt lv mas mg mgo mk ml mn mo mr ms mt my nah naq nb
+	nd ne nl nn nnh no nqo nr nso ny nyn om or os pa pap pl prg ps pt rm ro rof ru rwk sah saq sd sdh
+	se seh ses sg sh shi si sk sl sma smi smj smn sms sn so sq sr ss ssy st sv sw syr ta te teo th ti
+	tig tk tl tn to tr ts tzm ug uk ur uz ve vi vo vun wa wae wo xh xog yi yo yue zh zu
+`) {
+		languages[base] = true
+	}
+	return languages
+}()
+
+// hasCLDRPluralRules checks whether x/text provides plural rules for the given locale.
+// Locales x/text does not know (e.g. 'ru-Latn') are mapped to the root locale as well.
+func hasCLDRPluralRules(locale language.Tag) bool {
+	if index, _ := language.CompactIndex(locale); index == 0 {
+		return false
+	}
+	base, confidence := locale.Base()
+	return confidence != language.No && cldrPluralLanguages[base.String()]
+}
+
+// selectPluralCategory selects the plural category of a number using the first of the given locales
+// a custom or CLDR plural rule exists for
+func selectPluralCategory(locales []language.Tag, value *NumberValue) string {
+	// Plural rules only respect the digit options, the style does not affect them
+	options := DefaultNumberFormatOptions()
+	if value.Options != nil {
+		copied := *value.Options
+		copied.Style = NumberStyleDecimal
+		options = &copied
+	}
+	rounded := value.value.applyOptions(options)
+	ordinal := options.Type == NumberTypeOrdinal
+
+	// Like in JavaScript, NaN and the infinities always select the 'other' category
+	if rounded.nonFinite != "" {
+		return pluralStrings[plural.Other]
+	}
+
+	for _, locale := range locales {
+		if rule := lookupPluralRule(locale); rule != nil {
+			return rule(&NumberValue{value: rounded, Options: nil}, ordinal)
+		}
+		if hasCLDRPluralRules(locale) {
+			rules := plural.Cardinal
+			if ordinal {
+				rules = plural.Ordinal
+			}
+			digits, integers, fractions := rounded.pluralOperands()
+			return pluralStrings[rules.MatchDigits(locale, digits, integers, fractions)]
+		}
+	}
+	return pluralStrings[plural.Other]
+}
@@ -0,0 +1,50 @@
+package fluent
+
+import (
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+	"strings"
+	"testing"
+)
+
+// selectsOnlyOther checks whether the CLDR cardinal rules x/text applies to the locale always select 'other'
+func selectsOnlyOther(locale language.Tag) bool {
+	for _, number := range []string{"0", "1", "2", "3", "4", "5", "6", "7", "11", "12", "21", "22", "100", "1000000", "0.5", "1.5"} {
+		digits, _ := parseDecimal(number)
+		operands, integers, fractions := digits.pluralOperands()
+		if plural.Cardinal.MatchDigits(locale, operands, integers, fractions) != plural.Other {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCLDRPluralLanguages(t *testing.T) {
+	// cldrPluralLanguages has to be updated together with golang.org/x/text
+	if plural.CLDRVersion != "32" {
+		t.Fatalf("x/text ships CLDR %s, update cldrPluralLanguages", plural.CLDRVersion)
+	}
+
+	// The languages CLDR only defines the 'other' category for can not be told apart from unknown ones by probing
+	otherOnly := make(map[string]bool)
+	for _, base := range strings.Fields("bm bo dz id ig ii in ja jbo jv jw kde kea km ko lkt lo ms my nqo sah ses sg th to vi wo yo yue zh") {
+		otherOnly[base] = true
+	}
+	for base := range cldrPluralLanguages {
+		locale := language.Make(base)
+		if !hasCLDRPluralRules(locale) {
+			t.Fatalf("'%s' is not recognized as a language with plural rules", base)
+		}
+		if selectsOnlyOther(locale) != otherOnly[base] {
+			t.Fatalf("the plural rules x/text applies to '%s' do not match cldrPluralLanguages", base)
+		}
+	}
+
+	// Languages x/text knows without plural data fall back to the root locale
+	for _, base := range []string{"ewo", "dav", "ebu", "guz", "mer", "luo", "kln", "agq", "bas"} {
+		locale := language.Make(base)
+		if hasCLDRPluralRules(locale) || !selectsOnlyOther(locale) {
+			t.Fatalf("'%s' is expected to have no plural rules", base)
+		}
+	}
+}
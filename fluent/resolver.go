@@ -1,30 +1,44 @@
 package fluent
 
 import (
+	"errors"
 	"fmt"
 	"github.com/lus/fluent.go/fluent/parser/ast"
-	"golang.org/x/text/feature/plural"
-	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
-var pluralStrings = map[plural.Form]string{
-	plural.Other: "other",
-	plural.Zero:  "zero",
-	plural.One:   "one",
-	plural.Two:   "two",
-	plural.Few:   "few",
-	plural.Many:  "many",
-}
-
 // The resolver is used to resolve instances of as t.Pattern into instances of Value.
-// It uses context-relevant values and the initial Bundle for resolving specific values.
+// It uses context-relevant values and a snapshot of the initial Bundle for resolving specific values.
 type resolver struct {
 	bundle    *Bundle
+	state     *bundleState
+	messageID string
 	params    map[string]Value
 	variables map[string]Value
 	functions map[string]Function
 	errors    []error
+
+	// The errors of the variables that could not be converted into a Value; reported when they are referenced
+	invalidVariables map[string]error
+
+	// The patterns of the messages, terms and attributes that are currently being resolved and their names.
+	// These are used to detect cyclic references.
+	active     map[*ast.Pattern]bool
+	activeRefs []string
+
+	// The amount of placeables expanded so far and the error that aborted the resolving process, if any
+	placeables int
+	fatal      error
+}
+
+// errorContext describes the given node of the message currently being formatted
+func (resolver *resolver) errorContext(node ast.Node, span [2]uint) ErrorContext {
+	return ErrorContext{
+		MessageID: resolver.messageID,
+		Node:      node,
+		Span:      span,
+	}
 }
 
 func (resolver *resolver) resolveExpression(expression ast.Node) Value {
@@ -39,12 +53,15 @@ func (resolver *resolver) resolveExpression(expression ast.Node) Value {
 		return &StringValue{Value: e.Value}
 
 	case *ast.NumberLiteral:
-		parsed, err := strconv.ParseFloat(e.Value, 32)
-		if err != nil {
-			resolver.errors = append(resolver.errors, err)
+		parsed, ok := parseDecimal(e.Value)
+		if !ok {
+			resolver.errors = append(resolver.errors, &InvalidNumberError{
+				ErrorContext: resolver.errorContext(e, e.Span),
+				Value:        e.Value,
+			})
 			return &NoValue{value: "[" + e.Value + "]"}
 		}
-		return &NumberValue{Value: float32(parsed)}
+		return newPreciseNumber(parsed)
 
 	case *ast.MessageReference:
 		return resolver.resolveMessageReference(e)
@@ -67,9 +84,13 @@ func (resolver *resolver) resolveExpression(expression ast.Node) Value {
 }
 
 func (resolver *resolver) resolveMessageReference(ref *ast.MessageReference) Value {
-	message := resolver.bundle.messages[ref.ID.Name]
+	message := resolver.state.messages[ref.ID.Name]
 	if message == nil {
-		resolver.errors = append(resolver.errors, fmt.Errorf("unknown message '%s'", ref.ID.Name))
+		resolver.errors = append(resolver.errors, &UnknownMessageError{
+			ErrorContext: resolver.errorContext(ref, ref.Span),
+			ID:           ref.ID.Name,
+			Attribute:    "",
+		})
 		return &NoValue{
 			value: ref.ID.Name,
 		}
@@ -84,28 +105,39 @@ func (resolver *resolver) resolveMessageReference(ref *ast.MessageReference) Val
 			}
 		}
 		if attribute == nil {
-			resolver.errors = append(resolver.errors, fmt.Errorf("unknown message attribute '%s.%s'", ref.ID.Name, ref.Attribute.Name))
+			resolver.errors = append(resolver.errors, &UnknownMessageError{
+				ErrorContext: resolver.errorContext(ref, ref.Span),
+				ID:           ref.ID.Name,
+				Attribute:    ref.Attribute.Name,
+			})
 			return &NoValue{
 				value: ref.ID.Name + "." + ref.Attribute.Name,
 			}
 		}
-		return resolver.resolvePattern(attribute.Value)
+		return resolver.resolveEntryPattern(ref.ID.Name+"."+ref.Attribute.Name, attribute.Value)
 	}
 
 	if message.Value == nil {
-		resolver.errors = append(resolver.errors, fmt.Errorf("message '%s' has no value", ref.ID.Name))
+		resolver.errors = append(resolver.errors, &NoValueError{
+			ErrorContext: resolver.errorContext(ref, ref.Span),
+			ID:           ref.ID.Name,
+		})
 		return &NoValue{
 			value: ref.ID.Name,
 		}
 	}
 
-	return resolver.resolvePattern(message.Value)
+	return resolver.resolveEntryPattern(ref.ID.Name, message.Value)
 }
 
 func (resolver *resolver) resolveTermReference(ref *ast.TermReference) Value {
-	term := resolver.bundle.terms[ref.ID.Name]
+	term := resolver.state.terms[ref.ID.Name]
 	if term == nil {
-		resolver.errors = append(resolver.errors, fmt.Errorf("unknown term '%s'", ref.ID.Name))
+		resolver.errors = append(resolver.errors, &UnknownTermError{
+			ErrorContext: resolver.errorContext(ref, ref.Span),
+			ID:           ref.ID.Name,
+			Attribute:    "",
+		})
 		return &NoValue{
 			value: ref.ID.Name,
 		}
@@ -120,7 +152,11 @@ func (resolver *resolver) resolveTermReference(ref *ast.TermReference) Value {
 			}
 		}
 		if attribute == nil {
-			resolver.errors = append(resolver.errors, fmt.Errorf("unknown term attribute '%s.%s'", ref.ID.Name, ref.Attribute.Name))
+			resolver.errors = append(resolver.errors, &UnknownTermError{
+				ErrorContext: resolver.errorContext(ref, ref.Span),
+				ID:           ref.ID.Name,
+				Attribute:    ref.Attribute.Name,
+			})
 			return &NoValue{
 				value: ref.ID.Name + "." + ref.Attribute.Name,
 			}
@@ -129,13 +165,16 @@ func (resolver *resolver) resolveTermReference(ref *ast.TermReference) Value {
 			_, params := resolver.assembleArguments(ref.Arguments)
 			resolver.params = params
 		}
-		resolved := resolver.resolvePattern(attribute.Value)
+		resolved := resolver.resolveEntryPattern("-"+ref.ID.Name+"."+ref.Attribute.Name, attribute.Value)
 		resolver.params = nil
 		return resolved
 	}
 
 	if term.Value == nil {
-		resolver.errors = append(resolver.errors, fmt.Errorf("term '%s' has no value", ref.ID.Name))
+		resolver.errors = append(resolver.errors, &NoValueError{
+			ErrorContext: resolver.errorContext(ref, ref.Span),
+			ID:           "-" + ref.ID.Name,
+		})
 		return &NoValue{
 			value: ref.ID.Name,
 		}
@@ -145,7 +184,7 @@ func (resolver *resolver) resolveTermReference(ref *ast.TermReference) Value {
 		_, params := resolver.assembleArguments(ref.Arguments)
 		resolver.params = params
 	}
-	resolved := resolver.resolvePattern(term.Value)
+	resolved := resolver.resolveEntryPattern("-"+ref.ID.Name, term.Value)
 	resolver.params = nil
 	return resolved
 }
@@ -166,7 +205,20 @@ func (resolver *resolver) resolveVariableReference(ref *ast.VariableReference) V
 		}
 	}
 	if variable == nil {
-		resolver.errors = append(resolver.errors, fmt.Errorf("unknown variable '$%s'", ref.ID.Name))
+		if err, invalid := resolver.invalidVariables[ref.ID.Name]; invalid && resolver.params == nil {
+			resolver.errors = append(resolver.errors, &InvalidVariableError{
+				ErrorContext: resolver.errorContext(ref, ref.Span),
+				Name:         ref.ID.Name,
+				Err:          err,
+			})
+			return &NoValue{
+				value: "$" + ref.ID.Name,
+			}
+		}
+		resolver.errors = append(resolver.errors, &UnknownVariableError{
+			ErrorContext: resolver.errorContext(ref, ref.Span),
+			Name:         ref.ID.Name,
+		})
 		return &NoValue{
 			value: "$" + ref.ID.Name,
 		}
@@ -177,20 +229,37 @@ func (resolver *resolver) resolveVariableReference(ref *ast.VariableReference) V
 func (resolver *resolver) resolveFunctionReference(ref *ast.FunctionReference) Value {
 	function := resolver.functions[ref.ID.Name]
 	if function == nil {
-		resolver.errors = append(resolver.errors, fmt.Errorf("unknown function '%s'", ref.ID.Name))
+		resolver.errors = append(resolver.errors, &UnknownFunctionError{
+			ErrorContext: resolver.errorContext(ref, ref.Span),
+			Name:         ref.ID.Name,
+		})
 		return &NoValue{
 			value: ref.ID.Name,
 		}
 	}
 
 	positional, named := resolver.assembleArguments(ref.Arguments)
-	return function(positional, named)
+	result := function(positional, named)
+	if result == nil {
+		result = &ErrorValue{Err: errors.New("no value returned")}
+	}
+	if failed, ok := result.(*ErrorValue); ok {
+		resolver.errors = append(resolver.errors, &FunctionError{
+			ErrorContext: resolver.errorContext(ref, ref.Span),
+			Name:         ref.ID.Name,
+			Err:          failed.Err,
+		})
+		return &NoValue{
+			value: ref.ID.Name + "()",
+		}
+	}
+	return result
 }
 
 func (resolver *resolver) resolveSelectExpression(ref *ast.SelectExpression) Value {
 	selector := resolver.resolveExpression(ref.Selector)
 	if _, ok := selector.(*NoValue); ok {
-		return resolver.resolveDefaultVariant(ref.Variants)
+		return resolver.resolveDefaultVariant(ref)
 	}
 
 	for _, variant := range ref.Variants {
@@ -199,16 +268,18 @@ func (resolver *resolver) resolveSelectExpression(ref *ast.SelectExpression) Val
 		}
 	}
 
-	return resolver.resolveDefaultVariant(ref.Variants)
+	return resolver.resolveDefaultVariant(ref)
 }
 
-func (resolver *resolver) resolveDefaultVariant(variants []*ast.Variant) Value {
-	for _, variant := range variants {
+func (resolver *resolver) resolveDefaultVariant(ref *ast.SelectExpression) Value {
+	for _, variant := range ref.Variants {
 		if variant.Default {
 			return resolver.resolvePattern(variant.Value)
 		}
 	}
-	resolver.errors = append(resolver.errors, fmt.Errorf("no default variant specified"))
+	resolver.errors = append(resolver.errors, &NoDefaultVariantError{
+		ErrorContext: resolver.errorContext(ref, ref.Span),
+	})
 	return &NoValue{
 		value: "???",
 	}
@@ -223,31 +294,118 @@ func (resolver *resolver) matchesVariant(selector, variant Value) bool {
 
 	if selNum, ok := selector.(*NumberValue); ok {
 		if varNum, ok := variant.(*NumberValue); ok {
-			return selNum.Value == varNum.Value
+			return selNum.value.equals(varNum.value)
 		}
 		if varStr, ok := variant.(*StringValue); ok {
-			category := pluralStrings[resolver.getPluralCategory(selNum.Value)]
-			return varStr.Value == category
+			return varStr.Value == resolver.getPluralCategory(selNum)
+		}
+	}
+
+	if selectable, ok := selector.(Selectable); ok {
+		switch key := variant.(type) {
+		case *StringValue:
+			return selectable.Match(key.Value, resolver.bundle.locales[0])
+		case *NumberValue:
+			return selectable.Match(key.Raw(), resolver.bundle.locales[0])
 		}
 	}
 
 	return false
 }
 
+// resolveEntryPattern resolves the pattern of a message, term or attribute with the given name.
+// If the pattern is already being resolved further up the reference chain, an error is raised instead of recursing endlessly.
+func (resolver *resolver) resolveEntryPattern(name string, pattern *ast.Pattern) Value {
+	if resolver.active[pattern] {
+		chain := append(append([]string{}, resolver.activeRefs...), name)
+		resolver.errors = append(resolver.errors, &CyclicReferenceError{
+			ErrorContext: resolver.errorContext(pattern, pattern.Span),
+			Chain:        chain,
+		})
+		return &NoValue{
+			value: "???",
+		}
+	}
+
+	resolver.active[pattern] = true
+	resolver.activeRefs = append(resolver.activeRefs, name)
+
+	resolved := resolver.resolvePattern(pattern)
+
+	delete(resolver.active, pattern)
+	resolver.activeRefs = resolver.activeRefs[:len(resolver.activeRefs)-1]
+	return resolved
+}
+
 func (resolver *resolver) resolvePattern(pattern *ast.Pattern) Value {
-	result := ""
+	isolate := resolver.state.useIsolating && len(pattern.Elements) > 1
+
+	var result strings.Builder
 	for _, element := range pattern.Elements {
+		if resolver.fatal != nil {
+			return &NoValue{
+				value: "???",
+			}
+		}
+
 		if text, ok := element.(*ast.Text); ok {
-			result += text.Value
+			result.WriteString(text.Value)
 			continue
 		}
-		result += resolver.resolveExpression(element.(*ast.Placeable).Expression).String()
+
+		// Enforce the limits of the bundle to protect against the exponential expansion of placeables
+		resolver.placeables++
+		if max := resolver.state.maxPlaceables; max > 0 && resolver.placeables > max {
+			resolver.fatal = fmt.Errorf("%w: %d, max allowed is %d", ErrTooManyPlaceables, resolver.placeables, max)
+			return &NoValue{
+				value: "???",
+			}
+		}
+
+		expression := element.(*ast.Placeable).Expression
+		formatted := resolver.formatValue(resolver.resolveExpression(expression))
+		if max := resolver.state.maxPlaceableLength; max > 0 && len(formatted) > max {
+			if length := utf8.RuneCountInString(formatted); length > max {
+				resolver.fatal = fmt.Errorf("%w: %d, max allowed is %d", ErrPlaceableTooLong, length, max)
+				return &NoValue{
+					value: "???",
+				}
+			}
+		}
+
+		// Wrap the placeable in bidi isolation marks unless it is a literal
+		if isolate && !isLiteral(expression) {
+			formatted = "\u2068" + formatted + "\u2069"
+		}
+		result.WriteString(formatted)
 	}
 	return &StringValue{
-		Value: result,
+		Value: result.String(),
 	}
 }
 
+// isLiteral checks whether an expression is a string or number literal
+func isLiteral(expression ast.Node) bool {
+	switch expression.(type) {
+	case *ast.StringLiteral, *ast.NumberLiteral:
+		return true
+	default:
+		return false
+	}
+}
+
+// formatValue turns a value into a string, respecting the primary locale of the bundle
+func (resolver *resolver) formatValue(value Value) string {
+	// Date time symbols are not available for every locale, so the fallback locales of the bundle are considered too
+	if dateTime, ok := value.(*DateTimeValue); ok {
+		return formatDateTime(dateTime.Value, dateTime.Options, resolver.bundle.locales...)
+	}
+	if formattable, ok := value.(Formattable); ok {
+		return formattable.Format(resolver.bundle.locales[0])
+	}
+	return value.String()
+}
+
 func (resolver *resolver) assembleArguments(args *ast.CallArguments) (positional []Value, named map[string]Value) {
 	positional = make([]Value, 0, len(args.Positional))
 	for _, arg := range args.Positional {
@@ -260,17 +418,7 @@ func (resolver *resolver) assembleArguments(args *ast.CallArguments) (positional
 	return
 }
 
-func (resolver *resolver) getPluralCategory(value float32) plural.Form {
-	format := fmt.Sprintf("%.2f", value)
-	parts := strings.Split(strings.TrimRight(format, "0"), ".")
-
-	bytes := make([]byte, len(parts[0])+len(parts[1]))
-	for i, digit := range parts[0] {
-		bytes[i] = byte(digit - 48)
-	}
-	for i, digit := range parts[1] {
-		bytes[i+len(parts[0])] = byte(digit - 48)
-	}
-
-	return plural.Cardinal.MatchDigits(resolver.bundle.locales[0], bytes, len(parts[0]), len(parts[1]))
+// getPluralCategory selects the plural category of a number using the locales of the bundle
+func (resolver *resolver) getPluralCategory(value *NumberValue) string {
+	return selectPluralCategory(resolver.bundle.pluralLocales, value)
 }
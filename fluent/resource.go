@@ -1,12 +1,18 @@
 package fluent
 
 import (
+	"fmt"
 	"github.com/lus/fluent.go/fluent/parser"
 	"github.com/lus/fluent.go/fluent/parser/ast"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"sort"
 )
 
 // Resource represents a collection of messages and terms extracted out of a FTL source
 type Resource struct {
+	name     string
 	messages []*ast.Message
 	terms    []*ast.Term
 }
@@ -15,10 +21,68 @@ type Resource struct {
 // Besides the Resource object, this method also returns all errors the parser stumbled upon during parsing.
 // As long as Resource.IsEmpty does not return false, at least something could be parsed successfully.
 func NewResource(source string) (*Resource, []*parser.Error) {
+	return newResource("", source)
+}
+
+// NewResourceFromReader reads the whole source from the given reader and parses it like NewResource does.
+// If the reader has a Name method (like *os.File), its name is recorded on the resource and the parser errors.
+// An error is returned if the reader fails.
+func NewResourceFromReader(reader io.Reader) (*Resource, []*parser.Error, error) {
+	source, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := ""
+	if named, ok := reader.(interface{ Name() string }); ok {
+		name = named.Name()
+	}
+	resource, errs := newResource(name, string(source))
+	return resource, errs, nil
+}
+
+// LoadResourceFile reads and parses the FTL file at the given path.
+// The path is recorded on the resource and the parser errors. An error is returned if the file can not be read.
+func LoadResourceFile(path string) (*Resource, []*parser.Error, error) {
+	source, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	resource, errs := newResource(path, string(source))
+	return resource, errs, nil
+}
+
+// LoadResourcesFS reads and parses every file of the file system matching the given pattern (see fs.Glob), e.g. files
+// embedded using '//go:embed locales'. The resources are returned in lexical order of their paths, which are recorded
+// on the resources and the parser errors. An error is returned if the pattern is malformed or if a file can not be read.
+func LoadResourcesFS(fsys fs.FS, pattern string) ([]*Resource, []*parser.Error, error) {
+	paths, err := fs.Glob(fsys, pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Strings(paths)
+
+	resources := make([]*Resource, 0, len(paths))
+	var errs []*parser.Error
+	for _, path := range paths {
+		source, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return nil, nil, err
+		}
+		resource, parserErrs := newResource(path, string(source))
+		resources = append(resources, resource)
+		errs = append(errs, parserErrs...)
+	}
+	return resources, errs, nil
+}
+
+// newResource parses the given source with the given name into a new Resource object
+func newResource(name, source string) (*Resource, []*parser.Error) {
 	// Parse the source string into an AST
-	parsed, errs := parser.New(source).Parse()
+	parsed, errs := parser.NewNamed(name, source).Parse()
 
 	resource := &Resource{
+		name:     name,
 		messages: make([]*ast.Message, 0),
 		terms:    make([]*ast.Term, 0),
 	}
@@ -36,6 +100,20 @@ func NewResource(source string) (*Resource, []*parser.Error) {
 	return resource, errs
 }
 
+// Name returns the name of the source the resource was parsed from, e.g. the path of the file it was read from.
+// It is empty for resources created using NewResource.
+func (resource *Resource) Name() string {
+	return resource.name
+}
+
+// errorf creates an error concerning the resource; the name of the resource is prepended if it has one
+func (resource *Resource) errorf(format string, args ...interface{}) error {
+	if resource.name != "" {
+		return fmt.Errorf("%s: "+format, append([]interface{}{resource.name}, args...)...)
+	}
+	return fmt.Errorf(format, args...)
+}
+
 // IsEmpty returns if no terms and no messages are present in the resource.
 // This can be the case if the parser could not parse any valid messages and terms.
 func (resource *Resource) IsEmpty() bool {
@@ -0,0 +1,82 @@
+package fluent
+
+import (
+	"golang.org/x/text/language"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadResourceFile(t *testing.T) {
+	path := filepath.Join("../test", "bundle", "attribute_only.ftl")
+	resource, errs, err := LoadResourceFile(path)
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("could not load resource: %v, %v", err, errs)
+	}
+	if resource.Name() != path || resource.IsEmpty() {
+		t.Fatalf("unexpected resource '%s'", resource.Name())
+	}
+
+	if _, _, err := LoadResourceFile(filepath.Join("../test", "bundle", "missing.ftl")); err == nil {
+		t.Fatal("expected an error for a missing file")
+	}
+}
+
+func TestNewResourceFromReader(t *testing.T) {
+	resource, errs, err := NewResourceFromReader(strings.NewReader("key = Value"))
+	if err != nil || len(errs) > 0 {
+		t.Fatalf("could not load resource: %v, %v", err, errs)
+	}
+	if resource.Name() != "" || resource.IsEmpty() {
+		t.Fatalf("unexpected resource '%s'", resource.Name())
+	}
+
+	// Files record their name
+	path := filepath.Join("../test", "bundle", "attribute_only.ftl")
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	resource, _, err = NewResourceFromReader(file)
+	if err != nil || resource.Name() != path {
+		t.Fatalf("unexpected resource '%v', %v", resource, err)
+	}
+}
+
+func TestLoadResourcesFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"locales/en/main.ftl":   {Data: []byte("hello = Hello\n")},
+		"locales/en/broken.ftl": {Data: []byte("valid = Valid\n\nbroken = Value }\n")},
+		"locales/en/notes.txt":  {Data: []byte("not a resource")},
+	}
+
+	resources, errs, err := LoadResourcesFS(fsys, "locales/en/*.ftl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 2 || resources[0].Name() != "locales/en/broken.ftl" || resources[1].Name() != "locales/en/main.ftl" {
+		t.Fatalf("unexpected resources %v", resources)
+	}
+
+	// Parser errors point to the file they were raised in
+	if len(errs) != 1 || errs[0].SourceName() != "locales/en/broken.ftl" {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	if !strings.HasPrefix(errs[0].Error(), "locales/en/broken.ftl:3:16: ") {
+		t.Fatalf("unexpected error message '%s'", errs[0])
+	}
+
+	// Duplicate entries are reported with the name of the resource
+	bundle := NewBundle(language.English)
+	bundle.AddResource(resources[1])
+	if addErrs := bundle.AddResource(resources[1]); len(addErrs) != 1 || !strings.HasPrefix(addErrs[0].Error(), "locales/en/main.ftl: ") {
+		t.Fatalf("unexpected errors %v", addErrs)
+	}
+
+	if _, _, err := LoadResourcesFS(fsys, "["); err == nil {
+		t.Fatal("expected an error for a malformed pattern")
+	}
+}
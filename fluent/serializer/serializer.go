@@ -0,0 +1,256 @@
+package serializer
+
+import (
+	"github.com/lus/fluent.go/fluent/parser/ast"
+	"strings"
+)
+
+// Serializer is used to turn an AST back into a FTL source
+type Serializer struct {
+	withJunk bool
+}
+
+// New creates a new FTL serializer.
+// If withJunk is true, Junk nodes are serialized using their original content; otherwise they are omitted.
+func New(withJunk bool) *Serializer {
+	return &Serializer{withJunk: withJunk}
+}
+
+// Serialize turns a whole resource into its canonical FTL source
+func (serializer *Serializer) Serialize(resource *ast.Resource) string {
+	var builder strings.Builder
+	hasEntries := false
+	for _, entry := range resource.Body {
+		if _, ok := entry.(*ast.Junk); ok && !serializer.withJunk {
+			continue
+		}
+		builder.WriteString(serializer.serializeEntry(entry, hasEntries))
+		hasEntries = true
+	}
+	return builder.String()
+}
+
+// SerializeEntry turns a single entry (Message, Term, Comment, GroupComment, ResourceComment or Junk) into FTL source.
+// Junk is serialized regardless of the configuration of the serializer.
+func (serializer *Serializer) SerializeEntry(entry ast.Node) string {
+	return serializer.serializeEntry(entry, false)
+}
+
+// serializeEntry serializes an entry; standalone comments following other entries are separated by a blank line
+func (serializer *Serializer) serializeEntry(entry ast.Node, hasEntries bool) string {
+	separator := ""
+	if hasEntries {
+		separator = "\n"
+	}
+
+	switch e := entry.(type) {
+	case *ast.Message:
+		return serializeMessage(e)
+	case *ast.Term:
+		return serializeTerm(e)
+	case *ast.Comment:
+		return separator + serializeComment(e.Content, "#") + "\n"
+	case *ast.GroupComment:
+		return separator + serializeComment(e.Content, "##") + "\n"
+	case *ast.ResourceComment:
+		return separator + serializeComment(e.Content, "###") + "\n"
+	case *ast.Junk:
+		return e.Content
+	default:
+		return ""
+	}
+}
+
+// serializeComment prefixes every line of the comment content
+func serializeComment(content, prefix string) string {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = prefix
+		} else {
+			lines[i] = prefix + " " + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// serializeMessage serializes a message including its comment and attributes
+func serializeMessage(message *ast.Message) string {
+	var builder strings.Builder
+	if message.Comment != nil {
+		builder.WriteString(serializeComment(message.Comment.Content, "#"))
+	}
+	builder.WriteString(message.ID.Name + " =")
+	if message.Value != nil {
+		builder.WriteString(serializePattern(message.Value))
+	}
+	for _, attribute := range message.Attributes {
+		builder.WriteString(serializeAttribute(attribute))
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+// serializeTerm serializes a term including its comment and attributes
+func serializeTerm(term *ast.Term) string {
+	var builder strings.Builder
+	if term.Comment != nil {
+		builder.WriteString(serializeComment(term.Comment.Content, "#"))
+	}
+	builder.WriteString("-" + term.ID.Name + " =")
+	builder.WriteString(serializePattern(term.Value))
+	for _, attribute := range term.Attributes {
+		builder.WriteString(serializeAttribute(attribute))
+	}
+	builder.WriteString("\n")
+	return builder.String()
+}
+
+// serializeAttribute serializes an attribute on its own indented line
+func serializeAttribute(attribute *ast.Attribute) string {
+	return "\n    ." + attribute.ID.Name + " =" + indentExceptFirstLine(serializePattern(attribute.Value))
+}
+
+// serializePattern serializes a pattern including the blank space separating it from the preceding '='
+func serializePattern(pattern *ast.Pattern) string {
+	var builder strings.Builder
+	for _, element := range pattern.Elements {
+		switch e := element.(type) {
+		case *ast.Text:
+			builder.WriteString(e.Value)
+		case *ast.Placeable:
+			builder.WriteString(serializePlaceable(e))
+		}
+	}
+
+	content := indentExceptFirstLine(builder.String())
+	if shouldStartOnNewLine(pattern) {
+		return "\n    " + content
+	}
+	return " " + content
+}
+
+// serializePlaceable serializes a placeable including its braces
+func serializePlaceable(placeable *ast.Placeable) string {
+	switch e := placeable.Expression.(type) {
+	case *ast.Placeable:
+		return "{" + serializePlaceable(e) + "}"
+	case *ast.SelectExpression:
+		// The select expression ends with a line break already
+		return "{ " + SerializeExpression(e) + "}"
+	default:
+		return "{ " + SerializeExpression(e) + " }"
+	}
+}
+
+// SerializeExpression turns a single expression (literals, references, select expressions and placeables) into FTL source
+func SerializeExpression(expression ast.Node) string {
+	switch e := expression.(type) {
+	case *ast.StringLiteral:
+		return "\"" + e.Value + "\""
+	case *ast.NumberLiteral:
+		return e.Value
+	case *ast.VariableReference:
+		return "$" + e.ID.Name
+	case *ast.TermReference:
+		result := "-" + e.ID.Name
+		if e.Attribute != nil {
+			result += "." + e.Attribute.Name
+		}
+		if e.Arguments != nil {
+			result += serializeCallArguments(e.Arguments)
+		}
+		return result
+	case *ast.MessageReference:
+		result := e.ID.Name
+		if e.Attribute != nil {
+			result += "." + e.Attribute.Name
+		}
+		return result
+	case *ast.FunctionReference:
+		return e.ID.Name + serializeCallArguments(e.Arguments)
+	case *ast.SelectExpression:
+		result := SerializeExpression(e.Selector) + " ->"
+		for _, variant := range e.Variants {
+			result += serializeVariant(variant)
+		}
+		return result + "\n"
+	case *ast.Placeable:
+		return serializePlaceable(e)
+	default:
+		return ""
+	}
+}
+
+// serializeVariant serializes a variant of a select expression on its own line
+func serializeVariant(variant *ast.Variant) string {
+	key := SerializeVariantKey(variant.Key)
+	value := indentExceptFirstLine(serializePattern(variant.Value))
+	if variant.Default {
+		return "\n   *[" + key + "]" + value
+	}
+	return "\n    [" + key + "]" + value
+}
+
+// SerializeVariantKey turns the key of a variant (Identifier or NumberLiteral) into FTL source
+func SerializeVariantKey(key ast.Node) string {
+	switch k := key.(type) {
+	case *ast.Identifier:
+		return k.Name
+	case *ast.NumberLiteral:
+		return k.Value
+	default:
+		return ""
+	}
+}
+
+// serializeCallArguments serializes the arguments of a term or function reference including the parentheses
+func serializeCallArguments(arguments *ast.CallArguments) string {
+	if arguments == nil {
+		return "()"
+	}
+	serialized := make([]string, 0, len(arguments.Positional)+len(arguments.Named))
+	for _, positional := range arguments.Positional {
+		serialized = append(serialized, SerializeExpression(positional))
+	}
+	for _, named := range arguments.Named {
+		serialized = append(serialized, named.Name.Name+": "+SerializeExpression(named.Value))
+	}
+	return "(" + strings.Join(serialized, ", ") + ")"
+}
+
+// indentExceptFirstLine indents every line except the first one by four spaces
+func indentExceptFirstLine(content string) string {
+	return strings.ReplaceAll(content, "\n", "\n    ")
+}
+
+// shouldStartOnNewLine checks whether a pattern has to be serialized as a block starting on the line after the '='
+func shouldStartOnNewLine(pattern *ast.Pattern) bool {
+	multiline := false
+	for _, element := range pattern.Elements {
+		switch e := element.(type) {
+		case *ast.Text:
+			if strings.Contains(e.Value, "\n") {
+				multiline = true
+			}
+		case *ast.Placeable:
+			if _, ok := e.Expression.(*ast.SelectExpression); ok {
+				multiline = true
+			}
+		}
+	}
+	if !multiline {
+		return false
+	}
+
+	// Due to the indentation requirement these characters may not appear as the first character on a new line
+	if len(pattern.Elements) > 0 {
+		if text, ok := pattern.Elements[0].(*ast.Text); ok && text.Value != "" {
+			switch text.Value[0] {
+			case '[', '.', '*':
+				return false
+			}
+		}
+	}
+	return true
+}
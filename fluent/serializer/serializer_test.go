@@ -0,0 +1,122 @@
+package serializer
+
+import (
+	"encoding/json"
+	"github.com/lus/fluent.go/fluent/parser"
+	"github.com/lus/fluent.go/fluent/parser/ast"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	// Collect the file names of the fixtures in the '../../test/fixtures' directory
+	var fileNames []string
+	filepath.Walk(filepath.Join("../../test", "fixtures"), func(path string, info fs.FileInfo, err error) error {
+		if info == nil || info.IsDir() {
+			return nil
+		}
+		// The fixtures using CR as EOL consist of a single text element that can not be reproduced exactly
+		if strings.HasSuffix(info.Name(), ".ftl") && !strings.HasPrefix(info.Name(), "cr_") {
+			fileNames = append(fileNames, info.Name())
+		}
+		return nil
+	})
+
+	for _, fileName := range fileNames {
+		input, err := ioutil.ReadFile(filepath.Join("../../test", "fixtures", fileName))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Parsing the serialized resource has to result in the same AST (excluding junk)
+		resource, _ := parser.New(string(input)).Parse()
+		serialized := New(false).Serialize(resource)
+		reparsed, errs := parser.New(serialized).Parse()
+		if len(errs) > 0 {
+			t.Fatalf("serialized fixture '%s' could not be parsed: %v\n%s", fileName, errs, serialized)
+		}
+
+		expected, err := json.Marshal(withoutJunk(resource))
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual, err := json.Marshal(reparsed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(expected) != string(actual) {
+			t.Fatalf("serialized fixture '%s' does not match the original AST:\n%s", fileName, serialized)
+		}
+
+		// Serializing is idempotent
+		if again := New(false).Serialize(reparsed); again != serialized {
+			t.Fatalf("serializing fixture '%s' twice yields different results:\n%s\n%s", fileName, serialized, again)
+		}
+	}
+}
+
+func TestSerialize(t *testing.T) {
+	input := `### Resource comment
+
+## Group comment
+
+# Message comment
+message = Hello, { $name }!
+    .title = { -brand(case: "nominative") }
+multiline =
+    First line
+    Second line
+select =
+    { NUMBER($count, minimumFractionDigits: 2) ->
+        [one] One
+        [1.5] One and a half
+       *[other] { $count } items
+    }
+-brand = Fluent
+broken = {
+`
+	expected := `### Resource comment
+
+
+## Group comment
+
+# Message comment
+message = Hello, { $name }!
+    .title = { -brand(case: "nominative") }
+multiline =
+    First line
+    Second line
+select =
+    { NUMBER($count, minimumFractionDigits: 2) ->
+        [one] One
+        [1.5] One and a half
+       *[other] { $count } items
+    }
+-brand = Fluent
+`
+	resource, _ := parser.New(input).Parse()
+
+	if serialized := New(false).Serialize(resource); serialized != expected {
+		t.Fatalf("unexpected serialization:\n%s", serialized)
+	}
+	if serialized := New(true).Serialize(resource); serialized != expected+"broken = {\n" {
+		t.Fatalf("unexpected serialization including junk:\n%s", serialized)
+	}
+}
+
+// withoutJunk returns a copy of the resource without its junk entries
+func withoutJunk(resource *ast.Resource) *ast.Resource {
+	body := []ast.Node{}
+	for _, entry := range resource.Body {
+		if _, ok := entry.(*ast.Junk); !ok {
+			body = append(body, entry)
+		}
+	}
+	return &ast.Resource{
+		Base: resource.Base,
+		Body: body,
+	}
+}
@@ -0,0 +1,144 @@
+package fluent
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// WithStruct creates a FormatContext with a variable for every exported field of the given struct or pointer to a struct.
+//
+// The names of the variables are the kebab-case names of the fields (e.g. 'FirstName' becomes 'first-name') unless
+// they are set using a tag like `fluent:"name"`. Fields tagged with `fluent:"-"` are skipped, fields tagged with
+// `fluent:",omitempty"` are skipped if they hold the zero value of their type.
+//
+// Fields holding structs that can not be converted into a Value themselves (see WithVariables) are expanded into
+// variables prefixed with the name of the field, e.g. 'address-city'. The fields of embedded structs are added without
+// a prefix unless the embedded struct is tagged with a name. Values that are no structs result in an empty context.
+func WithStruct(value interface{}) *FormatContext {
+	variables := make(map[string]interface{})
+	visited := make(map[uintptr]bool)
+	reflected := reflect.ValueOf(value)
+	for reflected.Kind() == reflect.Ptr && !reflected.IsNil() {
+		visited[reflected.Pointer()] = true
+		reflected = reflected.Elem()
+	}
+	if reflected.Kind() == reflect.Struct {
+		collectStructFields(reflected, "", variables, visited)
+	}
+	return WithVariables(variables)
+}
+
+// collectStructFields adds the fields of a struct to the given variables, prefixing their names with the given prefix.
+// visited holds the addresses of the structs that are currently being expanded through pointers to break cycles.
+func collectStructFields(reflected reflect.Value, prefix string, variables map[string]interface{}, visited map[uintptr]bool) {
+	structType := reflected.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		tag := field.Tag.Get("fluent")
+		if tag == "-" {
+			continue
+		}
+		name, options := tag, ""
+		if comma := strings.IndexByte(tag, ','); comma >= 0 {
+			name, options = tag[:comma], tag[comma+1:]
+		}
+		omitEmpty := false
+		for _, option := range strings.Split(options, ",") {
+			if option == "omitempty" {
+				omitEmpty = true
+			}
+		}
+
+		fieldValue := reflected.Field(i)
+		if omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+
+		if nested, pointer, ok := nestedStruct(fieldValue); ok {
+			if pointer != 0 {
+				if visited[pointer] {
+					continue
+				}
+				visited[pointer] = true
+			}
+			nestedPrefix := prefix
+			if !field.Anonymous || name != "" {
+				nestedPrefix = prefix + fieldName(field.Name, name) + "-"
+			}
+			collectStructFields(nested, nestedPrefix, variables, visited)
+			if pointer != 0 {
+				delete(visited, pointer)
+			}
+			continue
+		}
+
+		// Embedded fields of unexported types are only used to promote their exported fields
+		if field.PkgPath != "" {
+			continue
+		}
+
+		variables[prefix+fieldName(field.Name, name)] = fieldValue.Interface()
+	}
+}
+
+// nestedStruct checks whether the given field holds a struct that has to be expanded into multiple variables, i.e. a struct
+// or non-nil pointer to a struct that can not be converted into a Value. It returns the struct and the address it was
+// dereferenced from, if any.
+func nestedStruct(value reflect.Value) (reflect.Value, uintptr, bool) {
+	var pointer uintptr
+	current := value
+	for current.Kind() == reflect.Ptr || current.Kind() == reflect.Interface {
+		if current.IsNil() {
+			return reflect.Value{}, 0, false
+		}
+		if current.Kind() == reflect.Ptr {
+			pointer = current.Pointer()
+		}
+		current = current.Elem()
+	}
+	if current.Kind() != reflect.Struct {
+		return reflect.Value{}, 0, false
+	}
+	if !value.CanInterface() {
+		return current, pointer, true
+	}
+	if _, err := convertValue(value.Interface()); err == nil {
+		return reflect.Value{}, 0, false
+	}
+	return current, pointer, true
+}
+
+// fieldName returns the name of the variable of a field; the name set by the tag or the kebab-case name of the field
+func fieldName(field, tagged string) string {
+	if tagged != "" {
+		return tagged
+	}
+	return kebabCase(field)
+}
+
+// kebabCase converts a Go identifier like 'FirstName' or 'HTTPServer' into kebab-case ('first-name', 'http-server')
+func kebabCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, char := range runes {
+		if !unicode.IsUpper(char) {
+			builder.WriteRune(char)
+			continue
+		}
+		if i > 0 {
+			previous := runes[i-1]
+			startsWord := unicode.IsLower(previous) || unicode.IsDigit(previous) ||
+				(unicode.IsUpper(previous) && i+1 < len(runes) && unicode.IsLower(runes[i+1]))
+			if startsWord {
+				builder.WriteRune('-')
+			}
+		}
+		builder.WriteRune(unicode.ToLower(char))
+	}
+	return builder.String()
+}
@@ -0,0 +1,116 @@
+package fluent
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKebabCase(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"Name", "name"},
+		{"FirstName", "first-name"},
+		{"UserID", "user-id"},
+		{"HTTPServer", "http-server"},
+		{"Address2", "address2"},
+		{"Line2Text", "line2-text"},
+		{"ID", "id"},
+	}
+	for _, test := range tests {
+		if result := kebabCase(test.name); result != test.expected {
+			t.Fatalf("'%s' was converted to '%s', expected '%s'", test.name, result, test.expected)
+		}
+	}
+}
+
+type testAddress struct {
+	Street string
+	City   string `fluent:"town"`
+}
+
+type testAudit struct {
+	CreatedAt time.Time
+}
+
+type testUser struct {
+	testAudit
+	FirstName   string
+	LastName    string `fluent:"surname"`
+	UnreadCount int
+	Gender      testGender
+	Nickname    string `fluent:",omitempty"`
+	Password    string `fluent:"-"`
+	Address     testAddress
+	Billing     *testAddress `fluent:"invoice"`
+	Manager     *testUser
+	internal    string
+}
+
+func TestWithStruct(t *testing.T) {
+	bundle := newTestBundle(t, `
+greeting = Hello, { $first-name } { $surname }!
+unread = { $unread-count ->
+    [one] One unread message
+   *[other] { $unread-count } unread messages
+}
+gender = { $gender }
+nickname = { $nickname }
+password = { $password }
+address = { $address-street }, { $address-town }
+billing = { $invoice-town }
+manager = { $manager-first-name }
+created = { $created-at }
+`)
+	user := &testUser{
+		testAudit:   testAudit{CreatedAt: time.Date(2021, 9, 12, 0, 0, 0, 0, time.UTC)},
+		FirstName:   "Jane",
+		LastName:    "Doe",
+		UnreadCount: 1,
+		Gender:      "female",
+		Nickname:    "",
+		Password:    "secret",
+		Address:     testAddress{Street: "Main Street 1", City: "Springfield"},
+		Billing:     &testAddress{Street: "", City: "Shelbyville"},
+		Manager:     nil,
+		internal:    "",
+	}
+	user.Manager = &testUser{FirstName: "John", Manager: user}
+
+	tests := []struct {
+		key      string
+		expected string
+		valid    bool
+	}{
+		{"greeting", "Hello, Jane Doe!", true},
+		{"unread", "One unread message", true},
+		{"gender", "female", true},
+		{"nickname", "{$nickname}", false},
+		{"password", "{$password}", false},
+		{"address", "Main Street 1, Springfield", true},
+		{"billing", "Shelbyville", true},
+		{"manager", "John", true},
+		{"created", "9/12/2021", true},
+	}
+	for _, test := range tests {
+		result, errs, err := bundle.FormatMessage(test.key, WithStruct(user))
+		if err != nil {
+			t.Fatalf("message '%s' could not be formatted: %v", test.key, err)
+		}
+		if test.valid == (len(errs) > 0) {
+			t.Fatalf("message '%s' resolved with unexpected errors: %v", test.key, errs)
+		}
+		if result != test.expected {
+			t.Fatalf("message '%s' resolved to '%s', expected '%s'", test.key, result, test.expected)
+		}
+	}
+
+	// Structs are accepted by value as well, other values result in an empty context
+	if result, _, _ := bundle.FormatMessage("greeting", WithStruct(*user)); result != "Hello, Jane Doe!" {
+		t.Fatalf("the struct value resolved to '%s'", result)
+	}
+	if context := WithStruct(42); len(context.variables) > 0 || len(context.invalidVariables) > 0 {
+		t.Fatalf("a context with variables was created for a number")
+	}
+}
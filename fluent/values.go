@@ -1,10 +1,14 @@
 package fluent
 
-import "strconv"
+import (
+	"fmt"
+	"golang.org/x/text/language"
+	"strconv"
+	"time"
+)
 
-// TODO: Implement DateTimes
-
-// Function represents a function that builds a Value based on parameters
+// Function represents a function that builds a Value based on parameters.
+// Functions that can not produce a value return an ErrorValue; the resolver reports it as a FunctionError.
 type Function func(positional []Value, named map[string]Value) Value
 
 // A Value is the result of a resolving operation performed by the Resolver.
@@ -13,6 +17,22 @@ type Value interface {
 	String() string
 }
 
+// Formattable is implemented by values that are formatted depending on the locale, e.g. money or durations.
+// The resolver formats them using the primary locale of the bundle instead of calling String.
+type Formattable interface {
+	Value
+	Format(locale language.Tag) string
+}
+
+// Selectable is implemented by values that select variants of select expressions on their own,
+// e.g. a person matching the variant keys 'male' and 'female'.
+// Match reports whether the value matches the given variant key; numeric keys are passed in their plain representation.
+// If no key matches, the default variant is used.
+type Selectable interface {
+	Value
+	Match(key string, locale language.Tag) bool
+}
+
 // StringValue wraps a string in order to comply with the Value API
 type StringValue struct {
 	Value string
@@ -30,21 +50,112 @@ func String(val string) *StringValue {
 	}
 }
 
-// NumberValue wraps a number (float32 at the moment) in order to comply with the Value API
+// NumberValue wraps a number in order to comply with the Value API.
+// The number is stored as an exact decimal, so integers of any size and the precision of decimal literals are preserved.
+// If Options is set (e.g. by the NUMBER builtin function), it is used to format the number and to select its plural category.
+// Otherwise, the defaults of Intl.NumberFormat are used.
 type NumberValue struct {
-	Value float32
+	value   decimal
+	Options *NumberFormatOptions
 }
 
-// String formats a NumberValue into a string
+// String formats a NumberValue into a string using the conventions of English
 func (value *NumberValue) String() string {
-	// TODO: Simulate JavaScript number formatting
-	return strconv.FormatFloat(float64(value.Value), 'f', -1, 32)
+	return value.Format(language.English)
+}
+
+// Format formats a NumberValue into a string using the conventions of the given locale
+func (value *NumberValue) Format(locale language.Tag) string {
+	options := value.Options
+	if options == nil {
+		options = DefaultNumberFormatOptions()
+	}
+	return formatNumber(value.value, options, locale)
+}
+
+// Raw returns the plain representation of the wrapped number without any formatting applied (e.g. '-1234.50')
+func (value *NumberValue) Raw() string {
+	return value.value.String()
+}
+
+// Float64 returns the nearest float64 to the wrapped number
+func (value *NumberValue) Float64() float64 {
+	parsed, _ := strconv.ParseFloat(value.value.String(), 64)
+	return parsed
 }
 
 // Number returns a new NumberValue with the given value; used for variables
-func Number(val float32) *NumberValue {
+func Number(val float64) *NumberValue {
 	return &NumberValue{
-		Value: val,
+		value:   decimalFromFloat(val, 64),
+		Options: nil,
+	}
+}
+
+// NumberFromInt returns a new NumberValue with the given integer value; used for variables
+func NumberFromInt(val int64) *NumberValue {
+	parsed, _ := parseDecimal(strconv.FormatInt(val, 10))
+	return &NumberValue{
+		value:   parsed,
+		Options: nil,
+	}
+}
+
+// NumberFromUint returns a new NumberValue with the given unsigned integer value; used for variables
+func NumberFromUint(val uint64) *NumberValue {
+	parsed, _ := parseDecimal(strconv.FormatUint(val, 10))
+	return &NumberValue{
+		value:   parsed,
+		Options: nil,
+	}
+}
+
+// ParseNumber parses a decimal string like '-1234.50' or '1.5e3' into a new NumberValue without losing any precision.
+// Like number literals in FTL sources, the amount of fraction digits is kept when formatting the number.
+// Exponents beyond ±1000 are rejected.
+func ParseNumber(val string) (*NumberValue, error) {
+	parsed, ok := parseDecimal(val)
+	if !ok {
+		return nil, fmt.Errorf("'%s' is no valid decimal number", val)
+	}
+	return newPreciseNumber(parsed), nil
+}
+
+// newPreciseNumber creates a NumberValue that keeps the amount of fraction digits of the given decimal when it is formatted
+func newPreciseNumber(value decimal) *NumberValue {
+	number := &NumberValue{
+		value:   value,
+		Options: nil,
+	}
+	if len(value.fraction) > 0 {
+		number.Options = DefaultNumberFormatOptions()
+		number.Options.MinimumFractionDigits = len(value.fraction)
+	}
+	return number
+}
+
+// DateTimeValue wraps a time.Time in order to comply with the Value API.
+// If Options is set (e.g. by the DATETIME builtin function), it is used to format the date time.
+type DateTimeValue struct {
+	Value   time.Time
+	Options *DateTimeFormatOptions
+}
+
+// String formats a DateTimeValue into a string using the conventions of American English
+func (value *DateTimeValue) String() string {
+	return value.Format(language.AmericanEnglish)
+}
+
+// Format formats a DateTimeValue into a string using the conventions of the given locale
+func (value *DateTimeValue) Format(locale language.Tag) string {
+	return formatDateTime(value.Value, value.Options, locale)
+}
+
+// DateTime returns a new DateTimeValue with the given value; used for variables
+func DateTime(val time.Time) *DateTimeValue {
+	return &DateTimeValue{
+		Value:   val,
+		Options: nil,
 	}
 }
 
@@ -57,3 +168,14 @@ type NoValue struct {
 func (value *NoValue) String() string {
 	return "{" + value.value + "}"
 }
+
+// ErrorValue is returned by functions that could not produce a value, e.g. because of invalid arguments.
+// The resolver reports its error as a FunctionError and formats the call like a missing value.
+type ErrorValue struct {
+	Err error
+}
+
+// String returns the message of the error
+func (value *ErrorValue) String() string {
+	return value.Err.Error()
+}
@@ -0,0 +1,10 @@
+#!/bin/sh
+# Copies the structure fixtures of @fluent/syntax into this directory.
+# Usage: ./import.sh <path to a checkout of https://github.com/projectfluent/fluent.js>
+set -e
+
+source="${1:?usage: $0 <fluent.js checkout>}/fluent-syntax/test/fixtures_structure"
+target="$(dirname "$0")"
+
+cp "$source"/*.ftl "$source"/*.json "$target"/
+echo "Imported $(ls "$source"/*.ftl | wc -l) fixtures from $(git -C "$source" rev-parse HEAD 2>/dev/null || echo "an unknown commit")"